@@ -0,0 +1,149 @@
+package bank
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ip-05/quizzus/api/middleware"
+	"github.com/ip-05/quizzus/entity"
+)
+
+type Service interface {
+	GetQuestion(ID int, userID uint) (*entity.BankQuestion, error)
+	SearchQuestions(userID uint, filter entity.BankFilter) (*entity.BankPage, error)
+	CreateQuestion(body entity.CreateBankQuestion, ownerID uint) (*entity.BankQuestion, error)
+	UpdateQuestion(ID int, body entity.CreateBankQuestion, userID uint) (*entity.BankQuestion, error)
+	DeleteQuestion(ID int, userID uint) error
+}
+
+type GameService interface {
+	PropagateQuestion(bankID int, userID uint) error
+}
+
+type Controller struct {
+	service Service
+	games   GameService
+}
+
+func NewController(bankSvc Service, gameSvc GameService) *Controller {
+	return &Controller{service: bankSvc, games: gameSvc}
+}
+
+func (c Controller) Search(ctx *gin.Context) {
+	/*
+
+		/bank?q=tomato&tag=food&difficulty=easy&limit=20&cursor=123
+		Pass `next_cursor` of the previous page as `cursor` to get the next one
+
+	*/
+
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	cursor, _ := strconv.Atoi(ctx.Query("cursor"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	page, err := c.service.SearchQuestions(user.ID, entity.BankFilter{
+		Query:      ctx.Query("q"),
+		Tag:        ctx.Query("tag"),
+		Difficulty: ctx.Query("difficulty"),
+		Cursor:     uint(cursor),
+		Limit:      limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c Controller) Get(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	question, err := c.service.GetQuestion(id, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, question)
+}
+
+func (c Controller) Create(ctx *gin.Context) {
+	var body entity.CreateBankQuestion
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, err := c.service.CreateQuestion(body, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, question)
+}
+
+func (c Controller) Update(ctx *gin.Context) {
+	/*
+
+		/bank/:id?propagate=true
+		Also updates the questions of every game made from this one
+
+	*/
+
+	var body entity.CreateBankQuestion
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	propagate, _ := strconv.ParseBool(ctx.Query("propagate"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, err := c.service.UpdateQuestion(id, body, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if propagate {
+		if err := c.games.PropagateQuestion(id, user.ID); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, question)
+}
+
+func (c Controller) Delete(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	if err := c.service.DeleteQuestion(id, user.ID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Successfully deleted"})
+}
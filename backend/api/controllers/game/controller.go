@@ -1,22 +1,40 @@
 package game
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ip-05/quizzus/api/middleware"
 	"github.com/ip-05/quizzus/entity"
+	"github.com/ip-05/quizzus/formats"
 )
 
 type Service interface {
 	CreateGame(body entity.CreateGame, ownerID uint) (*entity.Game, error)
-	UpdateGame(body entity.UpdateGame, ID int, code string, ownerID uint) (*entity.Game, error)
+	CloneGame(ID int, code string, userID uint) (*entity.Game, error)
+	ImportGame(format string, r io.Reader, body entity.CreateGame, ownerID uint, dryRun bool) (*entity.ImportResult, error)
+	UpdateGame(body entity.UpdateGame, ID int, code string, userID uint) (*entity.Game, error)
 	DeleteGame(ID int, code string, userID uint) error
+	PublishGame(ID int, code string, userID uint) (*entity.Game, error)
+	RollbackGame(ID int, code string, number int, userID uint) (*entity.Game, error)
+	PropagateQuestion(bankID int, userID uint) error
 
 	GetGame(ID int, code string) (*entity.Game, error)
 	GetGamesByOwner(ID int, user int, limit int) (*[]entity.Game, error)
 	GetFavoriteGames(user int) (*[]entity.Game, error)
+	GetAnalytics(ID int, code string, userID uint) (*entity.GameAnalytics, error)
+	ExportGame(ID int, code string, userID uint, format string) ([]byte, error)
+	GetRevisions(ID int, code string, userID uint) ([]entity.GameRevision, error)
+	GetRevision(ID int, code string, number, compare int, userID uint) (*entity.GameRevision, error)
+
+	GetCollaborators(ID int, code string, userID uint) ([]*entity.Collaborator, error)
+	AddCollaborator(ID int, code string, body entity.CreateCollaborator, userID uint) (*entity.Collaborator, error)
+	RemoveCollaborator(ID int, code string, collaboratorID, userID uint) error
 
 	Favorite(ID int, userID int) bool
 }
@@ -49,6 +67,105 @@ func (c Controller) CreateGame(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, game)
 }
 
+func (c Controller) Publish(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	game, err := c.service.PublishGame(id, code, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, game)
+}
+
+func (c Controller) Clone(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	game, err := c.service.CloneGame(id, code, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, game)
+}
+
+// maxImportSize caps the size of an imported document.
+const maxImportSize = 1 << 20
+
+func (c Controller) Import(ctx *gin.Context) {
+	/*
+
+		/games/import?format=gift&dry_run=true - Import a JSON, GIFT, Aiken, CSV or QTI document, sent as the body or as
+		a multipart "file". topic, round_time, points and public set up the game, the topic defaulting to the
+		document's category. JSON exports bring their own settings, which only topic overrides. A dry run previews the game and every problem without saving anything.
+
+	*/
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	dryRun, _ := strconv.ParseBool(ctx.Query("dry_run"))
+	public, _ := strconv.ParseBool(ctx.Query("public"))
+	roundTime, err := strconv.Atoi(ctx.DefaultQuery("round_time", "20"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "round_time must be a number"})
+		return
+	}
+	points, err := strconv.ParseFloat(ctx.DefaultQuery("points", "10"), 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "points must be a number"})
+		return
+	}
+
+	settings := entity.CreateGame{
+		Topic:     ctx.Query("topic"),
+		RoundTime: roundTime,
+		Points:    points,
+		Public:    public,
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+
+	var body io.Reader = ctx.Request.Body
+	if ctx.ContentType() == "multipart/form-data" {
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer opened.Close()
+		body = opened
+	}
+
+	result, err := c.service.ImportGame(ctx.Query("format"), body, settings, user.ID, dryRun)
+	if err != nil {
+		response := gin.H{"error": err.Error()}
+		if result != nil {
+			response["errors"] = result.Errors
+			response["warnings"] = result.Warnings
+		}
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
 func (c Controller) Get(ctx *gin.Context) {
 	id, _ := strconv.Atoi(ctx.Param("id"))
 	code := ctx.Param("id")
@@ -62,11 +179,116 @@ func (c Controller) Get(ctx *gin.Context) {
 	authedUser, _ := ctx.Get("authedUser")
 	user := authedUser.(middleware.AuthedUser)
 
-	if user.ID != game.Owner {
+	if !game.Can(user.ID, entity.ActionView) {
 		ctx.JSON(http.StatusOK, gin.H{"message": "Game found", "topic": game.Topic})
 		return
 	}
 
+	ctx.Header("ETag", etag(game))
+	ctx.JSON(http.StatusOK, game)
+}
+
+func (c Controller) Analytics(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	analytics, err := c.service.GetAnalytics(id, code, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+func (c Controller) Export(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+	format := ctx.DefaultQuery("format", formats.JSON)
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	data, err := c.service.ExportGame(id, code, user.ID, format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"quiz.%s\"", formats.Extension(format)))
+	ctx.Data(http.StatusOK, formats.ContentType(format), data)
+}
+
+func (c Controller) Revisions(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	revisions, err := c.service.GetRevisions(id, code, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+func (c Controller) Revision(ctx *gin.Context) {
+	/*
+
+		/games/:id/revisions/3?compare=1 - Get revision 3 with the changes since revision 1
+		Without compare, the changes are since the revision before it
+
+	*/
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+	number, err := strconv.Atoi(ctx.Param("rev"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "revision must be a number"})
+		return
+	}
+	compare, _ := strconv.Atoi(ctx.Query("compare"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	revision, err := c.service.GetRevision(id, code, number, compare, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revision)
+}
+
+func (c Controller) Rollback(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+	number, err := strconv.Atoi(ctx.Param("rev"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "revision must be a number"})
+		return
+	}
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	game, err := c.service.RollbackGame(id, code, number, user.ID)
+	if errors.Is(err, entity.ErrStaleRevision) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "game": game})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, game)
 }
 
@@ -142,12 +364,21 @@ func (c Controller) Update(ctx *gin.Context) {
 	authedUser, _ := ctx.Get("authedUser")
 	user := authedUser.(middleware.AuthedUser)
 
+	if body.Revision == 0 {
+		body.Revision = ifMatch(ctx)
+	}
+
 	game, err := c.service.UpdateGame(body, id, code, user.ID)
+	if errors.Is(err, entity.ErrStaleRevision) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "game": game})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	ctx.Header("ETag", etag(game))
 	ctx.JSON(http.StatusOK, game)
 }
 
@@ -166,3 +397,71 @@ func (c Controller) Delete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Successfully deleted"})
 }
+
+func (c Controller) Collaborators(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	collaborators, err := c.service.GetCollaborators(id, code, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, collaborators)
+}
+
+func (c Controller) AddCollaborator(ctx *gin.Context) {
+	var body entity.CreateCollaborator
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collaborator, err := c.service.AddCollaborator(id, code, body, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, collaborator)
+}
+
+func (c Controller) RemoveCollaborator(ctx *gin.Context) {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+	collaboratorID, _ := strconv.Atoi(ctx.Param("userID"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	err := c.service.RemoveCollaborator(id, code, uint(collaboratorID), user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Successfully removed"})
+}
+
+// etag is the entity tag of a game, its revision number.
+func etag(game *entity.Game) string {
+	return strconv.Quote(strconv.Itoa(game.Revision))
+}
+
+// ifMatch reads the revision an update was made from out of its If-Match header, 0 if there is none.
+func ifMatch(ctx *gin.Context) int {
+	tag := strings.TrimPrefix(ctx.GetHeader("If-Match"), "W/")
+	revision, _ := strconv.Atoi(strings.Trim(tag, `"`))
+	return revision
+}
@@ -0,0 +1,91 @@
+package leaderboard
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ip-05/quizzus/api/middleware"
+	"github.com/ip-05/quizzus/entity"
+)
+
+type Service interface {
+	GetGlobal(query entity.LeaderboardQuery) ([]entity.PlayerStanding, error)
+	GetGame(ID int, code string, userID uint, query entity.LeaderboardQuery) ([]entity.PlayerStanding, error)
+	GetRatings(limit int) []entity.RatingStanding
+}
+
+type Controller struct {
+	service Service
+}
+
+func NewController(leaderboardSvc Service) *Controller {
+	return &Controller{service: leaderboardSvc}
+}
+
+func (c Controller) GetGlobal(ctx *gin.Context) {
+	/*
+
+		/leaderboards?window=all|week|month|season&sort=points|best|win_rate&limit=25
+
+	*/
+
+	standings, err := c.service.GetGlobal(parseQuery(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standings)
+}
+
+func (c Controller) GetGame(ctx *gin.Context) {
+	/*
+
+		/leaderboards/games/:id?window=all|week|month|season&sort=best|points|win_rate&limit=25
+		:id is either the game ID or its invite code
+
+	*/
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	code := ctx.Param("id")
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	standings, err := c.service.GetGame(id, code, user.ID, parseQuery(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, standings)
+}
+
+func (c Controller) GetRatings(ctx *gin.Context) {
+	/*
+
+		/leaderboards/ratings?limit=25
+
+	*/
+
+	ctx.JSON(http.StatusOK, c.service.GetRatings(parseQuery(ctx).Limit))
+}
+
+func parseQuery(ctx *gin.Context) entity.LeaderboardQuery {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+
+	window := ctx.Query("window")
+	if window == "" {
+		window = entity.WindowAll
+	}
+
+	return entity.LeaderboardQuery{
+		Window: window,
+		Sort:   ctx.Query("sort"),
+		Limit:  limit,
+	}
+}
@@ -1,8 +1,12 @@
 package session
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ip-05/quizzus/api/middleware"
@@ -11,9 +15,16 @@ import (
 
 type Service interface {
 	GetSession(ID, userID int) *entity.GameSession
-	GetSessions(userID, limit int) *[]entity.GameSession
+	GetSessions(userID int, filter entity.SessionFilter) *entity.SessionPage
 	NewSession(ID, userID, instID int) uint
 	EndSession(ID, userID, instID, questions, players int, points float64) uint
+	SaveAnswers(answers []*entity.Answer)
+	NewInstance(ID, revision, hostID, instID int) uint
+	EndInstance(instID, questions, players int) uint
+	GetResults(instID int, userID uint) (*entity.InstanceResults, error)
+	UnlockAchievements(instID int) map[uint][]*entity.Achievement
+	GetStats(userID, viewerID uint) *entity.ProfileStats
+	GetReview(instID int, userID uint) (*entity.Review, error)
 }
 
 type Controller struct {
@@ -25,17 +36,113 @@ func NewController(sessionSvc Service) *Controller {
 }
 
 func (c Controller) GetSessions(ctx *gin.Context) {
+	/*
+
+		/sessions?limit=10&cursor=123&game_id=1&from=2023-01-01&to=2023-02-01
+		Pass `next_cursor` of the previous page as `cursor` to get the next one
+
+	*/
+
 	limit, _ := strconv.Atoi(ctx.Query("limit"))
-	if limit == 0 {
+	if limit <= 0 || limit > 50 {
 		limit = 10
 	}
+	cursor, _ := strconv.Atoi(ctx.Query("cursor"))
+	gameID, _ := strconv.Atoi(ctx.Query("game_id"))
+
+	from, err := parseDate(ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (2006-01-02) or RFC 3339 time"})
+		return
+	}
+	to, err := parseDate(ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (2006-01-02) or RFC 3339 time"})
+		return
+	}
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	page := c.service.GetSessions(int(user.ID), entity.SessionFilter{
+		Cursor: uint(cursor),
+		Limit:  limit,
+		GameID: uint(gameID),
+		From:   from,
+		To:     to,
+	})
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func (c Controller) Export(ctx *gin.Context) {
+	instID, _ := strconv.Atoi(ctx.Param("instID"))
 
 	authedUser, _ := ctx.Get("authedUser")
 	user := authedUser.(middleware.AuthedUser)
 
-	sessions := c.service.GetSessions(int(user.ID), limit)
+	results, err := c.service.GetResults(instID, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("results-%d", results.InstanceID)
+
+	var write func(io.Writer, *entity.InstanceResults) error
+	var contentType string
+
+	format := ctx.DefaultQuery("format", "csv")
+	switch format {
+	case "json":
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+		ctx.JSON(http.StatusOK, results)
+		return
+	case "csv":
+		write, contentType = writeCSV, "text/csv"
+	case "xlsx":
+		write, contentType = writeXLSX, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, xlsx or json"})
+		return
+	}
+
+	// Render the whole file first, so a failure is reported instead of sending a truncated one.
+	var buf bytes.Buffer
+	if err := write(&buf, results); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", filename, format))
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (c Controller) Review(ctx *gin.Context) {
+	instID, _ := strconv.Atoi(ctx.Param("instID"))
+
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
+	review, err := c.service.GetReview(instID, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	ctx.JSON(http.StatusOK, sessions)
+	ctx.JSON(http.StatusOK, review)
 }
 
 func (c Controller) GetSession(ctx *gin.Context) {
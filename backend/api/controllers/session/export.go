@@ -0,0 +1,56 @@
+package session
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/ip-05/quizzus/entity"
+	"github.com/xuri/excelize/v2"
+)
+
+func writeCSV(w io.Writer, results *entity.InstanceResults) error {
+	header, rows := results.Table()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func writeXLSX(w io.Writer, results *entity.InstanceResults) error {
+	header, rows := results.Table()
+
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	for i, row := range append([][]string{header}, rows...) {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+			// Keep rank, points and correct count numeric so they can be summed in the sheet.
+			if i > 0 && j < 4 {
+				if number, err := strconv.ParseFloat(value, 64); err == nil {
+					values[j] = number
+				}
+			}
+		}
+
+		if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
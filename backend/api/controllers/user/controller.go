@@ -15,6 +15,9 @@ type Service interface {
 	DeleteUser(ID uint)
 	GetUserById(ID uint) *entity.User
 	GetUserByProvider(ID string, provider string) *entity.User
+	GetProfile(ID uint, viewerID uint) any
+	UpdateRatings(instID uint, leaderboard []entity.Leaderboard) map[uint]*entity.RatingChange
+	AwardXP(leaderboard []entity.Leaderboard, correct map[uint]int) map[uint]*entity.XPGain
 }
 
 type Controller struct {
@@ -29,10 +32,11 @@ func (c Controller) Get(ctx *gin.Context) {
 	var userID int
 	var err error
 
+	authedUser, _ := ctx.Get("authedUser")
+	user := authedUser.(middleware.AuthedUser)
+
 	id := ctx.Param("id")
 	if id == "me" {
-		authedUser, _ := ctx.Get("authedUser")
-		user := authedUser.(middleware.AuthedUser)
 		userID = int(user.ID)
 	} else {
 		userID, err = strconv.Atoi(id)
@@ -42,8 +46,7 @@ func (c Controller) Get(ctx *gin.Context) {
 		}
 	}
 
-	dbUser := c.service.GetUserById(uint(userID))
-	ctx.JSON(http.StatusOK, dbUser)
+	ctx.JSON(http.StatusOK, c.service.GetProfile(uint(userID), user.ID))
 }
 
 func (c Controller) Update(ctx *gin.Context) {
@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// Client is a single socket connection together with the protocol version it negotiated.
+// Replies are queued and written by a dedicated writer so a slow connection never blocks its sender.
+type Client struct {
+	Conn    *websocket.Conn
+	Version int
+
+	send         chan []byte
+	writeTimeout time.Duration
+	done         chan struct{}
+	stopped      chan struct{}
+	closeOnce    sync.Once
+}
+
+func NewClient(conn *websocket.Conn, version int, queueSize int, writeTimeout time.Duration) *Client {
+	return &Client{
+		Conn:         conn,
+		Version:      version,
+		send:         make(chan []byte, queueSize),
+		writeTimeout: writeTimeout,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+}
+
+// enqueue queues bytes for the writer without blocking. A client whose queue is full has fallen
+// too far behind and gets disconnected.
+func (c *Client) enqueue(bytes []byte) bool {
+	select {
+	case c.send <- bytes:
+		return true
+	default:
+		c.disconnect(websocket.StatusPolicyViolation, "outbound queue full")
+		return false
+	}
+}
+
+func (c *Client) disconnect(code websocket.StatusCode, reason string) {
+	c.closeOnce.Do(func() {
+		go c.Conn.Close(code, reason)
+	})
+}
+
+func (c *Client) write(bytes []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.writeTimeout)
+	defer cancel()
+
+	return c.Conn.Write(ctx, websocket.MessageText, bytes)
+}
+
+// writer writes queued messages until Close is called, then flushes whatever is left.
+func (c *Client) writer() {
+	defer close(c.stopped)
+
+	for {
+		select {
+		case bytes := <-c.send:
+			if err := c.write(bytes); err != nil {
+				c.disconnect(websocket.StatusInternalError, "write failed")
+				return
+			}
+		case <-c.done:
+			for {
+				select {
+				case bytes := <-c.send:
+					if err := c.write(bytes); err != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close stops the writer once the queued messages are flushed.
+func (c *Client) Close() {
+	close(c.done)
+	<-c.stopped
+}
@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"nhooyr.io/websocket"
+)
+
+func dialTestClient(t *testing.T, handler func(client *Client)) *websocket.Conn {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		handler(NewClient(conn, ProtocolVersion, 1, time.Second))
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.Nil(t, err)
+	return conn
+}
+
+func TestClientWriter(t *testing.T) {
+	conn := dialTestClient(t, func(client *Client) {
+		go client.writer()
+		MessageReply(false, "HELLO").Send(client)
+		client.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, bytes, err := conn.Read(ctx)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), "HELLO")
+}
+
+func TestClientQueueFull(t *testing.T) {
+	queued := make(chan []bool, 1)
+	conn := dialTestClient(t, func(client *Client) {
+		// No writer is running, so the second message overflows the queue.
+		queued <- []bool{client.enqueue([]byte("1")), client.enqueue([]byte("2"))}
+		client.Conn.Read(context.Background())
+	})
+
+	assert.Equal(t, []bool{true, false}, <-queued)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, _, err := conn.Read(ctx)
+	assert.Equal(t, websocket.StatusPolicyViolation, websocket.CloseStatus(err))
+}
+
+func TestHeartbeatTimeout(t *testing.T) {
+	w := CoreController{pingInterval: 10 * time.Millisecond, pingTimeout: 10 * time.Millisecond}
+	evicted := make(chan struct{})
+	conn := dialTestClient(t, func(client *Client) {
+		// Pongs are only read while the connection is being read, as the message handler does.
+		go client.Conn.Read(context.Background())
+		w.heartbeat(context.Background(), client)
+		close(evicted)
+	})
+
+	// The client does not read until then, so it never answers a ping.
+	<-evicted
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The timed out ping already tore the connection down, so there is no close frame to read.
+	_, _, err := conn.Read(ctx)
+	assert.NotNil(t, err)
+	assert.Nil(t, ctx.Err())
+}
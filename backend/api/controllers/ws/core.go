@@ -5,30 +5,49 @@ import (
 	"encoding/json"
 	"go/types"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ip-05/quizzus/config"
 	"github.com/ip-05/quizzus/utils"
 	"nhooyr.io/websocket"
 )
 
 type SocketMessage struct {
-	Message string          `json:"message"`
-	Data    json.RawMessage `json:"data"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id,omitempty"`
+	Data      json.RawMessage `json:"data"`
 }
 
 type SocketReply[D any] struct {
-	Error   bool   `json:"error"`
-	Message string `json:"message"`
-	Data    any    `json:"data"`
+	Version   int             `json:"version,omitempty"`
+	Error     bool            `json:"error"`
+	Message   string          `json:"message"`
+	Code      utils.ErrorCode `json:"code,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Seq       uint64          `json:"seq,omitempty"`
+	Data      any             `json:"data"`
 }
 
 type CoreController struct {
 	gameController *GameSocketController
+	pingInterval   time.Duration
+	pingTimeout    time.Duration
+	sendQueue      int
+	writeTimeout   time.Duration
 }
 
-func NewCoreController(game GameService, user UserService, session SessionService) *CoreController {
+func NewCoreController(cfg *config.Config, game GameService, user UserService, session SessionService) *CoreController {
+	gameController := NewGameSocketController(game, user, session)
+	gameController.AnswerGrace = time.Duration(cfg.Socket.AnswerGrace) * time.Millisecond
+	go gameController.ExpireLobbies(time.Duration(cfg.Socket.LobbyTimeout) * time.Second)
+
 	return &CoreController{
-		gameController: NewGameSocketController(game, user, session),
+		gameController: gameController,
+		pingInterval:   time.Duration(cfg.Socket.PingInterval) * time.Second,
+		pingTimeout:    time.Duration(cfg.Socket.PingTimeout) * time.Second,
+		sendQueue:      int(cfg.Socket.SendQueue),
+		writeTimeout:   time.Duration(cfg.Socket.WriteTimeout) * time.Second,
 	}
 }
 
@@ -47,10 +66,112 @@ func DataReply[D any](error bool, message string, data D) SocketReply[D] {
 	}
 }
 
-func (s SocketReply[D]) Send(conn *websocket.Conn) {
-	bytes, _ := json.Marshal(s)
+// ErrorReply builds an error reply. When sent through Reply, the message is set to the request it answers.
+func ErrorReply(code utils.ErrorCode, details any) SocketReply[any] {
+	return SocketReply[any]{
+		Error: true,
+		Code:  code,
+		Data:  details,
+	}
+}
+
+// legacyErrors are the messages legacy clients knew errors by, where they differ from the code.
+var legacyErrors = map[utils.ErrorCode]string{
+	utils.InvalidMessage:   "MESSAGE_ERROR",
+	utils.InvalidData:      "DATA_ERROR",
+	utils.InitFailed:       "INIT_ERROR",
+	utils.ConnectionError:  "HANDLER_ERROR",
+	utils.RoundNotStarted:  utils.RoundWaiting,
+	utils.RoundNotFinished: utils.InProgress,
+}
+
+// legacyError is the message a legacy client knows an error by. Game state errors were named after the
+// state the game was in.
+func legacyError(code utils.ErrorCode, details any) string {
+	if status, ok := details.(string); ok && code == utils.InvalidGameState {
+		return status
+	}
+	if message, ok := legacyErrors[code]; ok {
+		return message
+	}
+	return string(code)
+}
+
+func (s SocketReply[D]) encode(version int) ([]byte, error) {
+	if version == LegacyVersion {
+		// Legacy clients route errors by message, so the code takes its place.
+		if s.Error {
+			s.Message = legacyError(s.Code, s.Data)
+		}
+		s.Code = ""
+		s.RequestID = ""
+		s.Seq = 0
+	}
+	s.Version = version
+
+	return json.Marshal(s)
+}
+
+// WithSeq stamps s with a game sequence number, for broadcasts whose payload differs per member.
+func (s SocketReply[D]) WithSeq(seq uint64) SocketReply[D] {
+	s.Seq = seq
+	return s
+}
+
+// Broadcast sends reply to every member of game under the game's next sequence number.
+func Broadcast[D any](game *Game, reply SocketReply[D]) {
+	reply.Seq = game.NextSeq()
+	for _, member := range game.Members {
+		reply.Send(member.Conn)
+	}
+}
+
+// Send queues s for the client's writer and never blocks.
+func (s SocketReply[D]) Send(client *Client) {
+	bytes, _ := s.encode(client.Version)
+
+	client.enqueue(bytes)
+}
+
+// Reply sends s to the client whose message is being handled in ctx, echoing its request ID.
+func (s SocketReply[D]) Reply(ctx context.Context) {
+	client := ctx.Value("conn").(*Client)
+
+	if msg, ok := ctx.Value("message").(SocketMessage); ok {
+		s.RequestID = msg.RequestID
+		if s.Error && s.Message == "" {
+			s.Message = msg.Message
+		}
+	}
+
+	s.Send(client)
+}
+
+// heartbeat pings the client periodically and closes the connection once a pong does not arrive in time,
+// which ends the message handler and frees the user for a new socket. A ping interval of 0 turns it off.
+func (w CoreController) heartbeat(ctx context.Context, client *Client) {
+	if w.pingInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, w.pingTimeout)
+			err := client.Conn.Ping(pingCtx)
+			cancel()
 
-	conn.Write(context.Background(), websocket.MessageText, bytes)
+			if err != nil {
+				client.disconnect(websocket.StatusPolicyViolation, "heartbeat timeout")
+				return
+			}
+		}
+	}
 }
 
 func (w CoreController) messageHandler(ctx context.Context, conn *websocket.Conn) error {
@@ -70,31 +191,37 @@ func (w CoreController) messageHandler(ctx context.Context, conn *websocket.Conn
 				var msg SocketMessage
 				err = json.Unmarshal(message, &msg)
 				if err != nil {
-					DataReply(true, utils.MessageError, err.Error()).Send(conn)
+					ErrorReply(utils.InvalidMessage, err.Error()).Reply(ctx)
 					break
 				}
 
+				msgCtx := context.WithValue(ctx, "message", msg)
+
 				switch msg.Message {
 				case utils.JoinGame:
-					w.gameController.JoinGame(ctx, msg.Data)
+					w.gameController.JoinGame(msgCtx, msg.Data)
 				case utils.LeaveGame:
-					w.gameController.LeaveGame(ctx)
+					w.gameController.LeaveGame(msgCtx)
 				case utils.GetGame:
-					w.gameController.GetGame(ctx)
+					w.gameController.GetGame(msgCtx)
 				case utils.IsOwner:
-					w.gameController.IsOwner(ctx)
+					w.gameController.IsOwner(msgCtx)
 				case utils.StartGame:
-					w.gameController.StartGame(ctx)
+					w.gameController.StartGame(msgCtx)
 				case utils.ResetGame:
-					w.gameController.ResetGame(ctx)
+					w.gameController.ResetGame(msgCtx)
 				case utils.AnswerQuestion:
-					w.gameController.AnswerQuestion(ctx, msg.Data)
+					w.gameController.AnswerQuestion(msgCtx, msg.Data)
 				case utils.SendChat:
-					w.gameController.SendChat(ctx, msg.Data)
+					w.gameController.SendChat(msgCtx, msg.Data)
 				case utils.NextRound:
-					w.gameController.NextRound(ctx)
+					w.gameController.NextRound(msgCtx)
+				case utils.Resync:
+					w.gameController.Resync(msgCtx)
 				case utils.Ping:
-					MessageReply(false, utils.Pong).Send(conn)
+					MessageReply(false, utils.Pong).Reply(msgCtx)
+				default:
+					ErrorReply(utils.UnknownMessage, msg.Message).Reply(msgCtx)
 				}
 			}
 		}
@@ -104,25 +231,29 @@ func (w CoreController) messageHandler(ctx context.Context, conn *websocket.Conn
 func (w CoreController) HandleWS(c *gin.Context) {
 	conn, err := websocket.Accept(c.Writer, c.Request, &websocket.AcceptOptions{
 		OriginPatterns: []string{"*"},
+		Subprotocols:   Subprotocols(),
 	})
 	if err != nil {
 		c.String(http.StatusBadRequest, "the sky is falling")
+		return
 	}
 	defer conn.Close(websocket.StatusInternalError, "")
 
-	if websocket.CloseStatus(err) == websocket.StatusNormalClosure ||
-		websocket.CloseStatus(err) == websocket.StatusGoingAway {
-		return
-	}
+	client := NewClient(conn, negotiatedVersion(conn.Subprotocol()), w.sendQueue, w.writeTimeout)
+	go client.writer()
+	defer client.Close()
 
 	authedUser, _ := c.Get("authedUser")
 
-	ctx := context.WithValue(context.Background(), "authedUser", authedUser)
-	ctx = context.WithValue(ctx, "conn", conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx = context.WithValue(ctx, "authedUser", authedUser)
+	ctx = context.WithValue(ctx, "conn", client)
 
 	user, err := w.gameController.InitUser(ctx)
 	if err != nil {
-		DataReply(true, "INIT_ERROR", err.Error()).Send(conn)
+		ErrorReply(utils.InitFailed, err.Error()).Send(client)
 		return
 	}
 
@@ -130,9 +261,19 @@ func (w CoreController) HandleWS(c *gin.Context) {
 
 	defer w.gameController.CleanUser(ctx)
 
+	go w.heartbeat(ctx, client)
+
+	if client.Version != LegacyVersion {
+		DataReply(false, utils.Welcome, WelcomeData{
+			Version:   client.Version,
+			Supported: supportedVersions(),
+		}).Send(client)
+	}
+
 	err = w.messageHandler(ctx, conn)
-	if err != nil {
-		DataReply(true, "HANDLER_ERROR", err.Error()).Send(conn)
+	if err != nil && websocket.CloseStatus(err) != websocket.StatusNormalClosure &&
+		websocket.CloseStatus(err) != websocket.StatusGoingAway {
+		ErrorReply(utils.ConnectionError, err.Error()).Send(client)
 		return
 	}
 }
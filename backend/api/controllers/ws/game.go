@@ -5,7 +5,12 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"errors"
+	"math"
 	"math/big"
+	mathrand "math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jinzhu/copier"
@@ -13,15 +18,14 @@ import (
 	"github.com/ip-05/quizzus/api/middleware"
 	"github.com/ip-05/quizzus/entity"
 	"github.com/ip-05/quizzus/utils"
-	"nhooyr.io/websocket"
 )
 
 type User struct {
-	ID             uint            `json:"id"`
-	Name           string          `json:"name"`
-	ProfilePicture string          `json:"profile_picture"`
-	ActiveGame     *Game           `json:"-"`
-	Conn           *websocket.Conn `json:"-"`
+	ID             uint    `json:"id"`
+	Name           string  `json:"name"`
+	ProfilePicture string  `json:"profile_picture"`
+	ActiveGame     *Game   `json:"-"`
+	Conn           *Client `json:"-"`
 }
 
 type Game struct {
@@ -40,19 +44,95 @@ type Game struct {
 	Leaderboard   map[uint]float64 `json:"leaderboard"`
 	Data          *entity.Game     `json:"-"`
 	Rounds        map[int]*Round   `json:"-"`
+	StandbySince  time.Time        `json:"-"`
+
+	// Questions are the questions drawn from Data for the current run, in the order they are asked.
+	Questions []*entity.Question `json:"-"`
+
+	// seq is the sequence number of the last broadcast. Rounds advance it while the game is being sent, so
+	// it is only used atomically.
+	seq atomic.Uint64
+
+	rng    *mathrand.Rand
+	next   chan struct{}
+	closed chan struct{}
 }
 
+// Round keeps answers as indexes into the question's options. Orders holds the order each player was
+// shown the options in, so the index a player sends can be mapped back. Answers come in on the players'
+// connections while the game loop scores them, so rounds are only touched under the controller's mu.
 type Round struct {
-	Answers map[uint]uint
+	Answers    map[uint]uint
+	AnsweredAt map[uint]time.Time
+	Orders     map[uint][]int
+	StartedAt  time.Time
+	Deadline   time.Time
+}
+
+// option maps the index of an option as shown to userID back to its index in the question.
+func (r *Round) option(userID uint, shown uint) uint {
+	order, ok := r.Orders[userID]
+	if !ok || int(shown) >= len(order) {
+		return shown
+	}
+	return uint(order[shown])
+}
+
+// options returns the question's options in the order userID was shown them.
+func (r *Round) options(userID uint, options []*entity.Option) []*entity.Option {
+	order, ok := r.Orders[userID]
+	if !ok || len(order) != len(options) {
+		return options
+	}
+
+	ordered := make([]*entity.Option, len(options))
+	for i, index := range order {
+		ordered[i] = options[index]
+	}
+	return ordered
+}
+
+// reset takes a finished game back to its lobby for another run.
+func (g *Game) reset() {
+	g.Status = utils.Standby
+	g.StandbySince = time.Now()
+	g.RoundStatus = utils.RoundWaiting
+	g.CurrentRound = 0
+	g.Leaderboard = map[uint]float64{}
+	g.Rounds = map[int]*Round{}
+	g.Questions = nil
+}
+
+// NextSeq reserves the sequence number of the next broadcast, letting clients detect missed events.
+func (g *Game) NextSeq() uint64 {
+	return g.seq.Add(1)
+}
+
+// Seq returns the sequence number of the last broadcast.
+func (g *Game) Seq() uint64 {
+	return g.seq.Load()
+}
+
+// MarshalJSON adds the sequence number of the last broadcast to the game.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	type Fields Game
+	return json.Marshal(struct {
+		*Fields
+		Seq uint64 `json:"seq"`
+	}{(*Fields)(g), g.Seq()})
 }
 
 type GameSocketController struct {
+	mu       sync.Mutex
 	Users    map[uint]*User
 	Games    map[string]*Game
 	Game     GameService
 	User     UserService
 	Session  SessionService
 	GameTime int
+
+	// AnswerGrace is how long after a round deadline answers are still accepted, to absorb network latency.
+	AnswerGrace time.Duration
 }
 
 type GameService interface {
@@ -70,6 +150,10 @@ type GameService interface {
 type SessionService interface {
 	NewSession(ID, userID, instID int) uint
 	EndSession(ID, userID, instID, questions, players int, points float64) uint
+	NewInstance(ID, revision, hostID, instID int) uint
+	EndInstance(instID, questions, players int) uint
+	SaveAnswers(answers []*entity.Answer)
+	UnlockAchievements(instID int) map[uint][]*entity.Achievement
 }
 
 type UserService interface {
@@ -78,6 +162,8 @@ type UserService interface {
 	DeleteUser(ID uint)
 	GetUserById(ID uint) *entity.User
 	GetUserByProvider(ID string, provider string) *entity.User
+	UpdateRatings(instID uint, leaderboard []entity.Leaderboard) map[uint]*entity.RatingChange
+	AwardXP(leaderboard []entity.Leaderboard, correct map[uint]int) map[uint]*entity.XPGain
 }
 
 func NewGameSocketController(gameSvc GameService, userSvc UserService, sessionSvc SessionService) *GameSocketController {
@@ -101,6 +187,9 @@ func (c *GameSocketController) InitUser(ctx context.Context) (*User, error) {
 		return nil, errors.New("no user found")
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	_, found := c.Users[user.ID]
 	if found {
 		return nil, errors.New("user already exists on another socket")
@@ -110,7 +199,7 @@ func (c *GameSocketController) InitUser(ctx context.Context) (*User, error) {
 		ID:             user.ID,
 		Name:           user.Name,
 		ProfilePicture: user.Picture,
-		Conn:           ctx.Value("conn").(*websocket.Conn),
+		Conn:           ctx.Value("conn").(*Client),
 	}
 
 	return c.Users[user.ID], nil
@@ -122,7 +211,43 @@ func (c *GameSocketController) CleanUser(ctx context.Context) {
 		c.LeaveGame(ctx)
 	}
 
+	c.mu.Lock()
 	delete(c.Users, user.ID)
+	c.mu.Unlock()
+}
+
+// ExpireLobbies periodically closes lobbies that have been waiting in standby for longer than timeout. A
+// timeout of 0 keeps lobbies open.
+func (c *GameSocketController) ExpireLobbies(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	for now := range time.Tick(time.Minute) {
+		c.expireLobbies(now, timeout)
+	}
+}
+
+// expireLobbies closes the lobbies that have been in standby for longer than timeout at now.
+func (c *GameSocketController) expireLobbies(now time.Time, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, game := range c.Games {
+		if game.Status == utils.Standby && now.Sub(game.StandbySince) > timeout {
+			c.closeGame(game, utils.LobbyExpired)
+		}
+	}
+}
+
+// closeGame tells the members of game why it was deleted and removes it. Callers must hold c.mu.
+func (c *GameSocketController) closeGame(game *Game, reason any) {
+	Broadcast(game, DataReply(false, utils.GameDeleted, reason))
+	for _, member := range game.Members {
+		member.ActiveGame = nil
+	}
+	delete(c.Games, game.InviteCode)
+	close(game.closed)
 }
 
 type JoinGameData struct {
@@ -130,18 +255,16 @@ type JoinGameData struct {
 }
 
 func (c *GameSocketController) JoinGame(ctx context.Context, msgData json.RawMessage) {
-	conn := ctx.Value("conn").(*websocket.Conn)
-
 	var data JoinGameData
 	err := json.Unmarshal(msgData, &data)
 	if err != nil {
-		DataReply(true, "DATA_ERROR", err.Error()).Send(conn)
+		ErrorReply(utils.InvalidData, err.Error()).Reply(ctx)
 		return
 	}
 
 	user := ctx.Value("user").(*User)
 	if user.ActiveGame != nil {
-		MessageReply(true, utils.AlreadyInGame).Send(conn)
+		ErrorReply(utils.AlreadyInGame, nil).Reply(ctx)
 		return
 	}
 
@@ -149,44 +272,47 @@ func (c *GameSocketController) JoinGame(ctx context.Context, msgData json.RawMes
 	// g.DB.Preload("Questions.Options").Where("invite_code = ?", data.GameId).First(&game)
 	game, err := c.Game.GetGame(0, data.GameID)
 	if err != nil {
-		DataReply(true, "DATA_ERROR", err.Error()).Send(conn)
+		ErrorReply(utils.InvalidData, err.Error()).Reply(ctx)
 		return
 	}
 
 	if game.ID == 0 {
-		MessageReply(true, utils.GameNotFound).Send(conn)
+		ErrorReply(utils.GameNotFound, nil).Reply(ctx)
+		return
+	}
+
+	if !game.Published() {
+		ErrorReply(utils.GameNotPublished, nil).Reply(ctx)
 		return
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	value, ok := c.Games[game.InviteCode]
 	if ok {
-		for _, member := range value.Members {
-			DataReply(false, utils.UserJoined, user).Send(member.Conn)
-		}
+		Broadcast(value, DataReply(false, utils.UserJoined, user))
 
 		value.Members[user.ID] = user
 		user.ActiveGame = value
 		value.Leaderboard[user.ID] = 0
 
-		DataReply(false, utils.JoinedGame, value).Send(conn)
+		DataReply(false, utils.JoinedGame, value).Reply(ctx)
 		return
 	}
 
-	if game.Owner != user.ID {
-		MessageReply(true, utils.NotOwner).Send(conn)
+	if !game.Can(user.ID, entity.ActionHost) {
+		ErrorReply(utils.NotOwner, nil).Reply(ctx)
 		return
 	}
 
-	instID, _ := rand.Int(rand.Reader, big.NewInt(100000000000))
-
 	newGame := Game{
 		ID:            game.ID,
-		InstID:        int(instID.Int64()),
 		Status:        utils.Standby,
 		RoundStatus:   utils.RoundWaiting,
 		Points:        game.Points,
 		Topic:         game.Topic,
-		QuestionCount: len(game.Questions),
+		QuestionCount: game.QuestionCount(),
 		RoundTime:     game.RoundTime,
 		InviteCode:    game.InviteCode,
 		Members:       map[uint]*User{},
@@ -194,6 +320,10 @@ func (c *GameSocketController) JoinGame(ctx context.Context, msgData json.RawMes
 		Rounds:        map[int]*Round{},
 		Owner:         user,
 		Data:          game,
+		StandbySince:  time.Now(),
+		rng:           mathrand.New(mathrand.NewSource(time.Now().UnixNano())),
+		next:          make(chan struct{}, 1),
+		closed:        make(chan struct{}),
 	}
 
 	newGame.Members[user.ID] = user
@@ -201,7 +331,7 @@ func (c *GameSocketController) JoinGame(ctx context.Context, msgData json.RawMes
 
 	c.Games[newGame.InviteCode] = &newGame
 	user.ActiveGame = c.Games[newGame.InviteCode]
-	DataReply(false, utils.JoinedGame, newGame).Send(conn)
+	DataReply(false, utils.JoinedGame, &newGame).Reply(ctx)
 }
 
 type ChatData struct {
@@ -215,149 +345,174 @@ type ChatBroadcast struct {
 }
 
 func (c *GameSocketController) SendChat(ctx context.Context, msgData json.RawMessage) {
-	conn := ctx.Value("conn").(*websocket.Conn)
 	user := ctx.Value("user").(*User)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
 	var data ChatData
 	err := json.Unmarshal(msgData, &data)
 	if err != nil {
-		DataReply(true, "DATA_ERROR", err.Error()).Send(conn)
+		ErrorReply(utils.InvalidData, err.Error()).Reply(ctx)
 		return
 	}
 
-	game := user.ActiveGame
-	for _, member := range game.Members {
-		DataReply(false, utils.ReceiveChat, ChatBroadcast{Name: user.Name, Message: data.Message, UserID: user.ID}).Send(member.Conn)
+	// Legacy clients only get the chat broadcast, not an acknowledgement.
+	if user.Conn.Version != LegacyVersion {
+		MessageReply(false, utils.SendChat).Reply(ctx)
 	}
+	Broadcast(user.ActiveGame, DataReply(false, utils.ReceiveChat, ChatBroadcast{Name: user.Name, Message: data.Message, UserID: user.ID}))
 }
 
 func (c *GameSocketController) LeaveGame(ctx context.Context) {
-	conn := ctx.Value("conn").(*websocket.Conn)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	user := ctx.Value("user").(*User)
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
 	game := user.ActiveGame
 	if game.Owner == user {
-		for _, member := range game.Members {
-			member.ActiveGame = nil
-			MessageReply(false, utils.GameDeleted).Send(member.Conn)
-		}
-		delete(c.Games, game.InviteCode)
+		c.closeGame(game, nil)
 	}
 
 	delete(game.Members, user.ID)
 	user.ActiveGame = nil
-	MessageReply(false, utils.LeftGame).Send(conn)
+	MessageReply(false, utils.LeftGame).Reply(ctx)
 
-	for _, member := range game.Members {
-		DataReply(false, utils.UserLeft, user).Send(member.Conn)
-	}
+	Broadcast(game, DataReply(false, utils.UserLeft, user))
 }
 
 func (c *GameSocketController) GetGame(ctx context.Context) {
-	conn := ctx.Value("conn").(*websocket.Conn)
-
 	user := ctx.Value("user").(*User)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
-	DataReply(false, utils.GetGame, user.ActiveGame).Send(conn)
+	DataReply(false, utils.GetGame, user.ActiveGame).Reply(ctx)
 }
 
 func (c *GameSocketController) IsOwner(ctx context.Context) {
-	conn := ctx.Value("conn").(*websocket.Conn)
-
 	user := ctx.Value("user").(*User)
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
-	DataReply(false, utils.IsOwner, user.ActiveGame.Owner == user).Send(conn)
+	DataReply(false, utils.IsOwner, user.ActiveGame.Owner == user).Reply(ctx)
 }
 
+// StartGame counts down and starts the game's rounds. The lock is only held between ticks, so members can
+// still join and leave during the countdown.
 func (c *GameSocketController) StartGame(ctx context.Context) {
-	conn := ctx.Value("conn").(*websocket.Conn)
-
 	user := ctx.Value("user").(*User)
+	c.mu.Lock()
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		c.mu.Unlock()
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
-	if user.ActiveGame.Owner != user {
-		MessageReply(true, utils.NotOwner).Send(conn)
+	game := user.ActiveGame
+	if game.Owner != user {
+		c.mu.Unlock()
+		ErrorReply(utils.NotOwner, nil).Reply(ctx)
 		return
 	}
 
-	if user.ActiveGame.Status != utils.Standby {
-		MessageReply(true, user.ActiveGame.Status).Send(conn)
+	if game.Status != utils.Standby {
+		c.mu.Unlock()
+		ErrorReply(utils.InvalidGameState, game.Status).Reply(ctx)
 		return
 	}
 
-	user.ActiveGame.Status = utils.Starting
+	game.Status = utils.Starting
+	c.mu.Unlock()
+	if user.Conn.Version != LegacyVersion {
+		MessageReply(false, utils.StartGame).Reply(ctx)
+	}
 
 	n := c.GameTime
 	for range time.Tick(time.Second * 1) {
 		if n == 0 {
 			break
 		}
-		for _, member := range user.ActiveGame.Members {
-			DataReply(false, utils.Starting, n).Send(member.Conn)
-		}
+		c.mu.Lock()
+		Broadcast(game, DataReply(false, utils.Starting, n))
+		c.mu.Unlock()
 
 		n -= 1
 	}
-	for id, member := range user.ActiveGame.Members {
-		MessageReply(false, utils.InProgress).Send(member.Conn)
 
-		c.Session.NewSession(int(user.ActiveGame.ID), int(id), user.ActiveGame.InstID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The game is closed if its owner left during the countdown.
+	select {
+	case <-game.closed:
+		return
+	default:
 	}
-	user.ActiveGame.Status = utils.InProgress
-	go c.PlayRounds(user.ActiveGame)
 
+	Broadcast(game, MessageReply(false, utils.InProgress))
+	c.begin(game)
+	game.Status = utils.InProgress
+	go c.PlayRounds(game)
 }
 
-func (c *GameSocketController) ResetGame(ctx context.Context) {
-	conn := ctx.Value("conn").(*websocket.Conn)
+// begin records a new run of the game, drawing its questions. Every run is a new instance, so reruns of a
+// lobby keep their own sessions and answers.
+func (c *GameSocketController) begin(game *Game) {
+	instID, _ := rand.Int(rand.Reader, big.NewInt(100000000000))
+	game.InstID = int(instID.Int64())
+	game.Questions = game.Data.Draw(game.rng)
+
+	c.Session.NewInstance(int(game.ID), game.Data.Revision, int(game.Owner.ID), game.InstID)
+	for id := range game.Members {
+		c.Session.NewSession(int(game.ID), int(id), game.InstID)
+	}
+}
 
+func (c *GameSocketController) ResetGame(ctx context.Context) {
 	user := ctx.Value("user").(*User)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
 	if user.ActiveGame.Owner != user {
-		MessageReply(true, utils.NotOwner).Send(conn)
+		ErrorReply(utils.NotOwner, nil).Reply(ctx)
 		return
 	}
 
 	if user.ActiveGame.Status != utils.Finished {
-		MessageReply(true, user.ActiveGame.Status).Send(conn)
+		ErrorReply(utils.InvalidGameState, user.ActiveGame.Status).Reply(ctx)
 		return
 	}
 
-	user.ActiveGame.Status = utils.Standby
-	user.ActiveGame.RoundStatus = utils.RoundWaiting
-	user.ActiveGame.CurrentRound = 0
-	user.ActiveGame.Leaderboard = map[uint]float64{}
-	user.ActiveGame.Rounds = map[int]*Round{}
+	user.ActiveGame.reset()
 
-	DataReply(false, utils.ResetGame, user.ActiveGame).Send(conn)
+	DataReply(false, utils.ResetGame, user.ActiveGame).Reply(ctx)
 }
 
 type RoundData[T entity.Question | Question] struct {
-	Timer    int `json:"timer"`
-	Question *T  `json:"question"`
+	Timer      int   `json:"timer"`
+	Deadline   int64 `json:"deadline"`
+	ServerTime int64 `json:"server_time"`
+	Question   *T    `json:"question"`
 }
 
 type Option struct {
@@ -375,62 +530,230 @@ type FinishedReply struct {
 	Leaderboard map[uint]float64 `json:"leaderboard"`
 }
 
+// roundData describes the current round as seen by user: the owner gets the full question,
+// everybody else gets it without the correct options. Times are in unix milliseconds.
+func roundData(game *Game, user *User, now time.Time) any {
+	round := game.Rounds[game.CurrentRound]
+	question := game.Questions[game.CurrentRound]
+
+	timer := int(math.Ceil(round.Deadline.Sub(now).Seconds()))
+	if timer < 0 {
+		timer = 0
+	}
+
+	if game.Owner == user {
+		return RoundData[entity.Question]{Timer: timer, Deadline: round.Deadline.UnixMilli(), ServerTime: now.UnixMilli(), Question: question}
+	}
+
+	shown := *question
+	shown.Options = round.options(user.ID, question.Options)
+
+	hidden := Question{}
+	copier.Copy(&hidden, &shown)
+	return RoundData[Question]{Timer: timer, Deadline: round.Deadline.UnixMilli(), ServerTime: now.UnixMilli(), Question: &hidden}
+}
+
+// PlayRounds runs the game's rounds and then finishes it. It runs beside the members' connections, so it
+// holds c.mu whenever it touches the game, but not while waiting or writing to the database.
 func (c *GameSocketController) PlayRounds(game *Game) {
-	game.RoundStatus = utils.RoundInProgress
 	for {
-		if game.Status == utils.InProgress && game.RoundStatus == utils.RoundInProgress {
-			n := game.Data.RoundTime
-			question := Question{}
-			copier.Copy(&question, game.Data.Questions[game.CurrentRound])
-			game.Rounds[game.CurrentRound] = &Round{Answers: map[uint]uint{}}
-
-			for range time.Tick(time.Second * 1) {
-				if n == 0 {
-					for _, member := range game.Members {
-						choice := game.Rounds[game.CurrentRound].Answers[member.ID]
-						correct := game.Data.Questions[game.CurrentRound].Options[choice].Correct
-
-						if correct {
-							game.Leaderboard[member.ID] += game.Data.Points
-						}
-					}
-
-					for _, member := range game.Members {
-						choice := game.Rounds[game.CurrentRound].Answers[member.ID]
-						correct := game.Data.Questions[game.CurrentRound].Options[choice].Correct
-
-						DataReply(false, utils.RoundFinished, FinishedReply{Correct: correct, Options: game.Data.Questions[game.CurrentRound].Options, Leaderboard: game.Leaderboard}).Send(member.Conn)
-					}
-
-					game.RoundStatus = utils.RoundWaiting
-					game.CurrentRound += 1
-
-					break
-				}
-				for _, member := range game.Members {
-					if game.Owner == member {
-						DataReply(false, utils.RoundInProgress, RoundData[entity.Question]{Question: game.Data.Questions[game.CurrentRound], Timer: n}).Send(member.Conn)
-					} else {
-						DataReply(false, utils.RoundInProgress, RoundData[Question]{Question: &question, Timer: n}).Send(member.Conn)
-					}
-				}
+		c.playRound(game)
 
-				n -= 1
+		c.mu.Lock()
+		done := game.CurrentRound >= len(game.Questions)
+		c.mu.Unlock()
+		if done {
+			break
+		}
+
+		select {
+		case <-game.next:
+		case <-game.closed:
+			return
+		}
+	}
+
+	c.mu.Lock()
+	game.Status = utils.Finished
+	leaderboard := finalLeaderboard(game)
+	correct := correctAnswers(game)
+	c.mu.Unlock()
+
+	ratings := c.User.UpdateRatings(uint(game.InstID), leaderboard)
+	xp := c.User.AwardXP(leaderboard, correct)
+
+	c.mu.Lock()
+	seq := game.NextSeq()
+	for id, member := range game.Members {
+		if member.Conn.Version == LegacyVersion {
+			DataReply(false, utils.Finished, game.Leaderboard).WithSeq(seq).Send(member.Conn)
+			continue
+		}
+
+		DataReply(false, utils.Finished, FinishedData{
+			Leaderboard: game.Leaderboard,
+			Rating:      ratings[id],
+			XP:          xp[id],
+		}).WithSeq(seq).Send(member.Conn)
+	}
+
+	// The lobby can be reset as soon as the lock is released, so the sessions are ended with the points of
+	// this run.
+	points := make(map[uint]float64, len(game.Members))
+	for id := range game.Members {
+		points[id] = game.Leaderboard[id]
+	}
+	c.mu.Unlock()
+
+	for id, score := range points {
+		c.Session.EndSession(int(game.ID), int(id), game.InstID, game.QuestionCount, len(points)-1, score)
+	}
+	c.Session.EndInstance(game.InstID, game.QuestionCount, len(points)-1)
+
+	unlocked := c.Session.UnlockAchievements(game.InstID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, achievements := range unlocked {
+		// Legacy clients do not know ACHIEVEMENT_UNLOCKED.
+		member, ok := game.Members[id]
+		if !ok || member.Conn.Version == LegacyVersion {
+			continue
+		}
+
+		for _, achievement := range achievements {
+			DataReply(false, utils.AchievementUnlocked, achievement).Send(member.Conn)
+		}
+	}
+}
+
+// FinishedData is what GAME_FINISHED carries to versioned clients. Legacy clients get the leaderboard alone.
+type FinishedData struct {
+	Leaderboard map[uint]float64     `json:"leaderboard"`
+	Rating      *entity.RatingChange `json:"rating,omitempty"`
+	XP          *entity.XPGain       `json:"xp,omitempty"`
+}
+
+// correctAnswers counts each member's correct answers over the rounds played.
+func correctAnswers(game *Game) map[uint]int {
+	correct := make(map[uint]int)
+	for i, round := range game.Rounds {
+		options := game.Questions[i].Options
+		for id, choice := range round.Answers {
+			if int(choice) < len(options) && options[choice].Correct {
+				correct[id]++
 			}
-		} else {
-			if game.CurrentRound >= len(game.Data.Questions) {
-				game.Status = utils.Finished
+		}
+	}
+	return correct
+}
 
-				for id, member := range game.Members {
-					DataReply(false, utils.Finished, game.Leaderboard).Send(member.Conn)
+// finalLeaderboard ranks the members still in game by points, leaving out the owner.
+func finalLeaderboard(game *Game) []entity.Leaderboard {
+	leaderboard := make([]entity.Leaderboard, 0, len(game.Members))
+	for id, member := range game.Members {
+		leaderboard = append(leaderboard, entity.Leaderboard{
+			Name:   member.Name,
+			UserID: id,
+			Points: game.Leaderboard[id],
+		})
+	}
 
-					c.Session.EndSession(int(game.ID), int(id), game.InstID, game.QuestionCount, len(game.Members)-1, game.Leaderboard[id])
-				}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Points != leaderboard[j].Points {
+			return leaderboard[i].Points > leaderboard[j].Points
+		}
+		return leaderboard[i].UserID < leaderboard[j].UserID
+	})
 
-				break
+	return entity.RankLeaderboard(leaderboard, game.Owner.ID)
+}
+
+// playRound announces the round deadline once and scores the answers when it has passed. Clients on
+// the legacy protocol cannot count down locally, so they still get the remaining time every second.
+func (c *GameSocketController) playRound(game *Game) {
+	c.mu.Lock()
+	now := time.Now()
+	question := game.Questions[game.CurrentRound]
+	round := &Round{
+		Answers:    map[uint]uint{},
+		AnsweredAt: map[uint]time.Time{},
+		Orders:     map[uint][]int{},
+		StartedAt:  now,
+		Deadline:   now.Add(time.Duration(game.Data.RoundTime) * time.Second),
+	}
+	// The owner sees the options in their usual order, which is also what USER_ANSWERED reports.
+	for id := range game.Members {
+		if id != game.Owner.ID {
+			round.Orders[id] = entity.OptionOrder(len(question.Options), game.Data.ShuffleOptions, game.rng)
+		}
+	}
+	game.Rounds[game.CurrentRound] = round
+	game.RoundStatus = utils.RoundInProgress
+
+	seq := game.NextSeq()
+	for _, member := range game.Members {
+		DataReply(false, utils.RoundInProgress, roundData(game, member, now)).WithSeq(seq).Send(member.Conn)
+	}
+	c.mu.Unlock()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	finish := time.NewTimer(time.Until(round.Deadline) + c.AnswerGrace)
+	defer finish.Stop()
+
+	for waiting := true; waiting; {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			for _, member := range game.Members {
+				if member.Conn.Version == LegacyVersion {
+					DataReply(false, utils.RoundInProgress, roundData(game, member, time.Now())).Send(member.Conn)
+				}
 			}
+			c.mu.Unlock()
+		case <-finish.C:
+			waiting = false
 		}
 	}
+
+	c.mu.Lock()
+	answers := scoreRound(game, round, question)
+
+	seq = game.NextSeq()
+	for _, member := range game.Members {
+		DataReply(false, utils.RoundFinished, FinishedReply{Correct: answers[member.ID].Correct, Options: round.options(member.ID, question.Options), Leaderboard: game.Leaderboard}).WithSeq(seq).Send(member.Conn)
+	}
+
+	game.RoundStatus = utils.RoundWaiting
+	game.CurrentRound += 1
+	owner := game.Owner.ID
+	c.mu.Unlock()
+
+	// The owner sees the correct options, so their answers would only skew the results.
+	delete(answers, owner)
+	records := make([]*entity.Answer, 0, len(answers))
+	for _, answer := range answers {
+		records = append(records, answer)
+	}
+	c.Session.SaveAnswers(records)
+}
+
+// scoreRound turns everyone's choice in round into an answer of the game's current run and adds its points
+// to the leaderboard.
+func scoreRound(game *Game, round *Round, question *entity.Question) map[uint]*entity.Answer {
+	answers := map[uint]*entity.Answer{}
+	for _, member := range game.Members {
+		var option *entity.Option
+		if choice, ok := round.Answers[member.ID]; ok {
+			option = question.Options[choice]
+		}
+
+		answer := entity.NewAnswer(uint(game.InstID), game.ID, member.ID, question, option, round.AnsweredAt[member.ID].Sub(round.StartedAt), game.Data.Points)
+		game.Leaderboard[member.ID] += answer.Points
+		answers[member.ID] = answer
+	}
+	return answers
 }
 
 type AnswerData struct {
@@ -444,57 +767,104 @@ type AnswerResponse struct {
 
 func (c *GameSocketController) AnswerQuestion(ctx context.Context, msgData json.RawMessage) {
 	user := ctx.Value("user").(*User)
-	conn := ctx.Value("conn").(*websocket.Conn)
-
 	var data AnswerData
 	err := json.Unmarshal(msgData, &data)
 	if err != nil {
-		DataReply(true, "DATA_ERROR", err.Error()).Send(conn)
+		ErrorReply(utils.InvalidData, err.Error()).Reply(ctx)
 		return
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
+		return
+	}
+
+	game := user.ActiveGame
+	round := game.Rounds[game.CurrentRound]
+	if game.RoundStatus != utils.RoundInProgress || game.Status != utils.InProgress || round == nil {
+		ErrorReply(utils.RoundNotStarted, game.RoundStatus).Reply(ctx)
 		return
 	}
 
-	if user.ActiveGame.RoundStatus == utils.RoundInProgress && user.ActiveGame.Status == utils.InProgress {
-		user.ActiveGame.Rounds[user.ActiveGame.CurrentRound].Answers[user.ID] = data.Option
-		DataReply(false, utils.AnswerAccepted, user.ActiveGame).Send(conn)
-		DataReply(false, utils.UserAnswered, AnswerResponse{
-			UserID: user.ID,
-			Option: data.Option,
-		}).Send(user.ActiveGame.Owner.Conn)
-	} else {
-		MessageReply(true, utils.RoundWaiting).Send(conn)
+	if time.Now().After(round.Deadline.Add(c.AnswerGrace)) {
+		ErrorReply(utils.DeadlinePassed, round.Deadline.UnixMilli()).Reply(ctx)
+		return
 	}
+
+	if int(data.Option) >= len(game.Questions[game.CurrentRound].Options) {
+		ErrorReply(utils.InvalidData, "option out of range").Reply(ctx)
+		return
+	}
+
+	option := round.option(user.ID, data.Option)
+	round.Answers[user.ID] = option
+	round.AnsweredAt[user.ID] = time.Now()
+	DataReply(false, utils.AnswerAccepted, game).Reply(ctx)
+	DataReply(false, utils.UserAnswered, AnswerResponse{
+		UserID: user.ID,
+		Option: option,
+	}).Send(game.Owner.Conn)
 }
 
 func (c *GameSocketController) NextRound(ctx context.Context) {
-	conn := ctx.Value("conn").(*websocket.Conn)
-
 	user := ctx.Value("user").(*User)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if user.ActiveGame == nil {
-		MessageReply(true, utils.NotInGame).Send(conn)
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
 		return
 	}
 
 	if user.ActiveGame.Owner != user {
-		MessageReply(true, utils.NotOwner).Send(conn)
+		ErrorReply(utils.NotOwner, nil).Reply(ctx)
 		return
 	}
 
 	if user.ActiveGame.Status != utils.InProgress {
-		MessageReply(true, user.ActiveGame.Status).Send(conn)
+		ErrorReply(utils.InvalidGameState, user.ActiveGame.Status).Reply(ctx)
 		return
 	}
 
 	if user.ActiveGame.RoundStatus != utils.RoundWaiting {
-		MessageReply(true, utils.InProgress).Send(conn)
+		ErrorReply(utils.RoundNotFinished, user.ActiveGame.RoundStatus).Reply(ctx)
 		return
 	}
 
 	user.ActiveGame.RoundStatus = utils.RoundInProgress
+	select {
+	case user.ActiveGame.next <- struct{}{}:
+	default:
+	}
+
+	MessageReply(false, utils.RoundInProgress).Reply(ctx)
+}
+
+type ResyncData struct {
+	Game  *Game `json:"game"`
+	Round any   `json:"round,omitempty"`
+}
+
+// Resync sends the full game state so a client that noticed a gap in sequence numbers can catch up.
+func (c *GameSocketController) Resync(ctx context.Context) {
+	user := ctx.Value("user").(*User)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if user.ActiveGame == nil {
+		ErrorReply(utils.NotInGame, nil).Reply(ctx)
+		return
+	}
+
+	game := user.ActiveGame
+	data := ResyncData{Game: game}
+
+	if game.Status == utils.InProgress && game.RoundStatus == utils.RoundInProgress && game.Rounds[game.CurrentRound] != nil {
+		data.Round = roundData(game, user, time.Now())
+	}
 
-	MessageReply(false, utils.RoundInProgress).Send(conn)
+	DataReply(false, utils.Resync, data).Reply(ctx)
 }
@@ -0,0 +1,241 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	mathrand "math/rand"
+	"testing"
+	"time"
+
+	"github.com/ip-05/quizzus/entity"
+	"github.com/ip-05/quizzus/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func testGame() (*Game, *User, *User) {
+	owner := &User{ID: 1}
+	player := &User{ID: 2}
+
+	game := &Game{
+		Owner:   owner,
+		Members: map[uint]*User{1: owner, 2: player},
+		Data: &entity.Game{
+			RoundTime: 10,
+			Points:    3,
+			Questions: []*entity.Question{
+				{
+					Name: "What color is tomato?",
+					Options: []*entity.Option{
+						{Name: "Red", Correct: true},
+						{Name: "Green", Correct: false},
+					},
+				},
+			},
+		},
+		Rounds: map[int]*Round{},
+	}
+	game.Questions = game.Data.Questions
+
+	return game, owner, player
+}
+
+func TestRoundData(t *testing.T) {
+	game, owner, player := testGame()
+	now := time.Now()
+	game.Rounds[0] = &Round{Deadline: now.Add(9500 * time.Millisecond)}
+
+	t.Run("TestOwner", func(t *testing.T) {
+		data := roundData(game, owner, now).(RoundData[entity.Question])
+		assert.Equal(t, 10, data.Timer)
+		assert.Equal(t, game.Rounds[0].Deadline.UnixMilli(), data.Deadline)
+		assert.Equal(t, now.UnixMilli(), data.ServerTime)
+		assert.True(t, data.Question.Options[0].Correct)
+	})
+
+	t.Run("TestPlayer", func(t *testing.T) {
+		data := roundData(game, player, now).(RoundData[Question])
+		assert.Equal(t, "Red", data.Question.Options[0].Name)
+	})
+
+	t.Run("TestPastDeadline", func(t *testing.T) {
+		data := roundData(game, player, now.Add(time.Minute)).(RoundData[Question])
+		assert.Equal(t, 0, data.Timer)
+	})
+
+	t.Run("TestShuffledOptions", func(t *testing.T) {
+		game.Rounds[0].Orders = map[uint][]int{player.ID: {1, 0}}
+
+		data := roundData(game, player, now).(RoundData[Question])
+		assert.Equal(t, "Green", data.Question.Options[0].Name)
+		assert.Equal(t, "Red", data.Question.Options[1].Name)
+
+		owner := roundData(game, owner, now).(RoundData[entity.Question])
+		assert.Equal(t, "Red", owner.Question.Options[0].Name)
+	})
+}
+
+func TestRoundOptions(t *testing.T) {
+	game, owner, player := testGame()
+	options := game.Questions[0].Options
+	round := &Round{Orders: map[uint][]int{player.ID: {1, 0}}}
+
+	assert.Equal(t, uint(0), round.option(player.ID, 1))
+	assert.Equal(t, uint(1), round.option(player.ID, 0))
+	assert.Equal(t, uint(1), round.option(owner.ID, 1))
+
+	assert.Equal(t, "Green", round.options(player.ID, options)[0].Name)
+	assert.Equal(t, "Red", round.options(owner.ID, options)[0].Name)
+}
+
+func TestFinalLeaderboard(t *testing.T) {
+	game, _, _ := testGame()
+	game.Members[3] = &User{ID: 3}
+	game.Members[4] = &User{ID: 4}
+	game.Leaderboard = map[uint]float64{1: 9, 2: 3, 3: 6, 4: 3}
+
+	actual := finalLeaderboard(game)
+
+	assert.Len(t, actual, 3)
+	assert.Equal(t, uint(3), actual[0].UserID)
+	assert.Equal(t, 1, actual[0].Rank)
+	assert.Equal(t, uint(2), actual[1].UserID)
+	assert.Equal(t, 2, actual[1].Rank)
+	assert.Equal(t, 2, actual[2].Rank)
+}
+
+func TestCorrectAnswers(t *testing.T) {
+	game, _, player := testGame()
+	game.Members[3] = &User{ID: 3}
+	game.Rounds[0] = &Round{Answers: map[uint]uint{player.ID: 0, 3: 1}}
+
+	actual := correctAnswers(game)
+
+	assert.Equal(t, map[uint]int{player.ID: 1}, actual)
+}
+
+// testSessions records the instances and sessions a game opens.
+type testSessions struct {
+	SessionService
+	instances []int
+	sessions  map[int][]int
+	answers   []*entity.Answer
+}
+
+func (s *testSessions) NewInstance(ID, revision, hostID, instID int) uint {
+	s.instances = append(s.instances, instID)
+	return uint(instID)
+}
+
+func (s *testSessions) NewSession(ID, userID, instID int) uint {
+	s.sessions[instID] = append(s.sessions[instID], userID)
+	return uint(userID)
+}
+
+func (s *testSessions) SaveAnswers(answers []*entity.Answer) {
+	s.answers = append(s.answers, answers...)
+}
+
+func TestRerun(t *testing.T) {
+	game, _, player := testGame()
+	game.rng = mathrand.New(mathrand.NewSource(1))
+	game.Leaderboard = map[uint]float64{}
+	sessions := &testSessions{sessions: map[int][]int{}}
+	c := &GameSocketController{Session: sessions}
+
+	play := func() map[uint]*entity.Answer {
+		c.begin(game)
+		round := &Round{Answers: map[uint]uint{player.ID: 0}, StartedAt: time.Now()}
+		return scoreRound(game, round, game.Questions[0])
+	}
+
+	first := play()
+	game.Status = utils.Finished
+	game.reset()
+	second := play()
+
+	assert.Len(t, sessions.instances, 2)
+	assert.NotEqual(t, sessions.instances[0], sessions.instances[1])
+	for i, answers := range []map[uint]*entity.Answer{first, second} {
+		assert.ElementsMatch(t, []int{1, 2}, sessions.sessions[sessions.instances[i]])
+		assert.Equal(t, uint(sessions.instances[i]), answers[player.ID].InstanceID)
+	}
+	assert.Equal(t, second[player.ID].Points, game.Leaderboard[player.ID])
+}
+
+func TestAnswerDuringRound(t *testing.T) {
+	game, owner, player := testGame()
+	game.rng = mathrand.New(mathrand.NewSource(1))
+	game.Leaderboard = map[uint]float64{}
+	game.Status = utils.InProgress
+	game.Data.RoundTime = 0
+	owner.Conn = NewClient(nil, ProtocolVersion, 16, time.Second)
+	player.Conn = NewClient(nil, ProtocolVersion, 16, time.Second)
+	owner.ActiveGame, player.ActiveGame = game, game
+	sessions := &testSessions{}
+	c := &GameSocketController{Session: sessions, AnswerGrace: 200 * time.Millisecond}
+
+	played := make(chan struct{})
+	go func() {
+		c.playRound(game)
+		close(played)
+	}()
+
+	// The round has started once its deadline reaches the player.
+	<-player.Conn.send
+	ctx := context.WithValue(context.WithValue(context.Background(), "user", player), "conn", player.Conn)
+	c.AnswerQuestion(ctx, json.RawMessage(`{"option": 0}`))
+	<-played
+
+	assert.Len(t, sessions.answers, 1)
+	assert.Equal(t, player.ID, sessions.answers[0].UserID)
+	assert.Greater(t, game.Leaderboard[player.ID], 0.0)
+	assert.Equal(t, 1, game.CurrentRound)
+}
+
+func TestExpireLobbies(t *testing.T) {
+	now := time.Now()
+	lobby := func(code, status string, since time.Duration) *Game {
+		member := &User{ID: 1, Conn: NewClient(nil, ProtocolVersion, 16, time.Second)}
+		game := &Game{
+			InviteCode:   code,
+			Status:       status,
+			StandbySince: now.Add(-since),
+			Members:      map[uint]*User{member.ID: member},
+			closed:       make(chan struct{}),
+		}
+		member.ActiveGame = game
+		return game
+	}
+
+	expired := lobby("expired", utils.Standby, time.Hour)
+	waiting := lobby("waiting", utils.Standby, time.Minute)
+	playing := lobby("playing", utils.InProgress, time.Hour)
+	c := &GameSocketController{Games: map[string]*Game{"expired": expired, "waiting": waiting, "playing": playing}}
+
+	c.expireLobbies(now, 30*time.Minute)
+
+	assert.Equal(t, map[string]*Game{"waiting": waiting, "playing": playing}, c.Games)
+	assert.Nil(t, expired.Members[1].ActiveGame)
+	assert.Contains(t, string(<-expired.Members[1].Conn.send), utils.GameDeleted)
+	assert.Empty(t, waiting.Members[1].Conn.send)
+}
+
+func TestChatAck(t *testing.T) {
+	for _, tc := range []struct {
+		version int
+		first   string
+	}{
+		{LegacyVersion, utils.ReceiveChat},
+		{ProtocolVersion, utils.SendChat},
+	} {
+		game, _, player := testGame()
+		game.Members = map[uint]*User{player.ID: player}
+		player.ActiveGame = game
+		player.Conn = NewClient(nil, tc.version, 16, time.Second)
+		ctx := context.WithValue(context.WithValue(context.Background(), "user", player), "conn", player.Conn)
+
+		(&GameSocketController{}).SendChat(ctx, json.RawMessage(`{"message": "hi"}`))
+
+		assert.Contains(t, string(<-player.Conn.send), tc.first)
+	}
+}
@@ -0,0 +1,58 @@
+package ws
+
+import (
+	_ "embed"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// LegacyVersion is assumed for clients that connect without negotiating a subprotocol.
+	LegacyVersion = 0
+	// ProtocolVersion is the newest protocol version spoken by the server.
+	ProtocolVersion = 1
+)
+
+// subprotocols maps the WebSocket subprotocols offered during the handshake to protocol versions.
+var subprotocols = map[string]int{
+	"quizzus.v1": 1,
+}
+
+//go:embed schema.json
+var schema []byte
+
+type WelcomeData struct {
+	Version   int   `json:"version"`
+	Supported []int `json:"supported"`
+}
+
+func Subprotocols() []string {
+	names := make([]string, 0, len(subprotocols))
+	for name := range subprotocols {
+		names = append(names, name)
+	}
+	return names
+}
+
+func negotiatedVersion(subprotocol string) int {
+	version, ok := subprotocols[subprotocol]
+	if !ok {
+		return LegacyVersion
+	}
+	return version
+}
+
+func supportedVersions() []int {
+	versions := []int{LegacyVersion}
+	for _, version := range subprotocols {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+func (w CoreController) Schema(c *gin.Context) {
+	c.Data(http.StatusOK, "application/schema+json", schema)
+}
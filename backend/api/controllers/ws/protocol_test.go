@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ip-05/quizzus/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSchema struct {
+	Version     int    `json:"version"`
+	Subprotocol string `json:"subprotocol"`
+	Defs        struct {
+		ErrorCode struct {
+			Enum []utils.ErrorCode `json:"enum"`
+		} `json:"error_code"`
+	} `json:"$defs"`
+	Client map[string]json.RawMessage `json:"client"`
+	Server map[string]json.RawMessage `json:"server"`
+}
+
+func TestSchema(t *testing.T) {
+	var s testSchema
+	err := json.Unmarshal(schema, &s)
+	assert.Nil(t, err)
+
+	assert.Equal(t, ProtocolVersion, s.Version)
+	assert.Equal(t, ProtocolVersion, negotiatedVersion(s.Subprotocol))
+	assert.ElementsMatch(t, utils.ErrorCodes, s.Defs.ErrorCode.Enum)
+
+	for _, message := range []string{utils.JoinGame, utils.AnswerQuestion, utils.Resync, utils.Ping} {
+		assert.Contains(t, s.Client, message)
+	}
+	for _, message := range []string{utils.Welcome, utils.Resync, utils.RoundFinished, utils.Finished} {
+		assert.Contains(t, s.Server, message)
+	}
+}
+
+func TestNegotiatedVersion(t *testing.T) {
+	assert.Equal(t, LegacyVersion, negotiatedVersion(""))
+	assert.Equal(t, LegacyVersion, negotiatedVersion("quizzus.v999"))
+	assert.Equal(t, []int{LegacyVersion, ProtocolVersion}, supportedVersions())
+}
+
+func TestEncodeReply(t *testing.T) {
+	reply := ErrorReply(utils.NotOwner, nil)
+	reply.Message = utils.StartGame
+	reply.RequestID = "42"
+	reply.Seq = 7
+
+	t.Run("TestLegacy", func(t *testing.T) {
+		bytes, err := reply.encode(LegacyVersion)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"error":true,"message":"NOT_OWNER","data":null}`, string(bytes))
+	})
+
+	t.Run("TestLegacyMessages", func(t *testing.T) {
+		for expected, reply := range map[string]SocketReply[any]{
+			"DATA_ERROR":       ErrorReply(utils.InvalidData, "option out of range"),
+			utils.RoundWaiting: ErrorReply(utils.RoundNotStarted, utils.RoundWaiting),
+			utils.Finished:     ErrorReply(utils.InvalidGameState, utils.Finished),
+		} {
+			bytes, err := reply.encode(LegacyVersion)
+			assert.Nil(t, err)
+
+			var actual SocketReply[any]
+			assert.Nil(t, json.Unmarshal(bytes, &actual))
+			assert.Equal(t, expected, actual.Message)
+		}
+	})
+
+	t.Run("TestVersioned", func(t *testing.T) {
+		bytes, err := reply.encode(ProtocolVersion)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"version":1,"error":true,"message":"START_GAME","code":"NOT_OWNER","request_id":"42","seq":7,"data":null}`, string(bytes))
+	})
+}
+
+func TestNextSeq(t *testing.T) {
+	game := &Game{}
+
+	assert.Equal(t, uint64(1), game.NextSeq())
+	assert.Equal(t, uint64(2), game.NextSeq())
+	assert.Equal(t, uint64(2), game.Seq())
+
+	bytes, err := json.Marshal(game)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), `"seq":2`)
+}
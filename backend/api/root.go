@@ -4,7 +4,9 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	authController "github.com/ip-05/quizzus/api/controllers/auth"
+	bankController "github.com/ip-05/quizzus/api/controllers/bank"
 	gameController "github.com/ip-05/quizzus/api/controllers/game"
+	leaderboardController "github.com/ip-05/quizzus/api/controllers/leaderboard"
 	sessionController "github.com/ip-05/quizzus/api/controllers/session"
 	userController "github.com/ip-05/quizzus/api/controllers/user"
 	ws "github.com/ip-05/quizzus/api/controllers/ws"
@@ -21,6 +23,8 @@ func InitWeb(
 	authSvc authController.AuthService,
 	userSvc userController.Service,
 	sessionSvc sessionController.Service,
+	leaderboardSvc leaderboardController.Service,
+	bankSvc bankController.Service,
 ) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
@@ -35,8 +39,10 @@ func InitWeb(
 	sessionsController := sessionController.NewController(sessionSvc)
 	authController := authController.NewController(cfg, gcfg, authSvc, userSvc)
 	gameController := gameController.NewController(gameSvc)
+	leaderboardController := leaderboardController.NewController(leaderboardSvc)
+	bankController := bankController.NewController(bankSvc, gameSvc)
 
-	ws := ws.NewCoreController(gameSvc, userSvc, sessionSvc)
+	ws := ws.NewCoreController(cfg, gameSvc, userSvc, sessionSvc)
 
 	userGroup := router.Group("users")
 	{
@@ -59,18 +65,49 @@ func InitWeb(
 	{
 		gamesGroup.Use(middleware.AuthMiddleware(cfg))
 		gamesGroup.GET("/:id", gameController.Get)
+		gamesGroup.GET("/:id/analytics", gameController.Analytics)
+		gamesGroup.GET("/:id/export", gameController.Export)
+		gamesGroup.GET("/:id/revisions", gameController.Revisions)
+		gamesGroup.GET("/:id/revisions/:rev", gameController.Revision)
+		gamesGroup.POST("/:id/revisions/:rev/rollback", gameController.Rollback)
 		gamesGroup.GET("", gameController.GetMany)
 		gamesGroup.POST("/:id/favorite", gameController.Favorite)
+		gamesGroup.POST("/:id/clone", gameController.Clone)
+		gamesGroup.POST("/:id/publish", gameController.Publish)
+		gamesGroup.GET("/:id/collaborators", gameController.Collaborators)
+		gamesGroup.POST("/:id/collaborators", gameController.AddCollaborator)
+		gamesGroup.DELETE("/:id/collaborators/:userID", gameController.RemoveCollaborator)
 		gamesGroup.POST("", gameController.CreateGame)
+		gamesGroup.POST("/import", gameController.Import)
 		gamesGroup.PATCH("", gameController.Update)
 		gamesGroup.DELETE("", gameController.Delete)
 	}
 
+	bankGroup := router.Group("bank")
+	{
+		bankGroup.Use(middleware.AuthMiddleware(cfg))
+		bankGroup.GET("", bankController.Search)
+		bankGroup.GET("/:id", bankController.Get)
+		bankGroup.POST("", bankController.Create)
+		bankGroup.PATCH("/:id", bankController.Update)
+		bankGroup.DELETE("/:id", bankController.Delete)
+	}
+
 	sessionsGroup := router.Group("sessions")
 	{
 		sessionsGroup.Use(middleware.AuthMiddleware(cfg))
 		sessionsGroup.GET("", sessionsController.GetSessions)
 		sessionsGroup.GET("/:id", sessionsController.GetSession)
+		sessionsGroup.GET("/instances/:instID/export", sessionsController.Export)
+		sessionsGroup.GET("/instances/:instID/review", sessionsController.Review)
+	}
+
+	leaderboardsGroup := router.Group("leaderboards")
+	{
+		leaderboardsGroup.Use(middleware.AuthMiddleware(cfg))
+		leaderboardsGroup.GET("", leaderboardController.GetGlobal)
+		leaderboardsGroup.GET("/games/:id", leaderboardController.GetGame)
+		leaderboardsGroup.GET("/ratings", leaderboardController.GetRatings)
 	}
 
 	wsGroup := router.Group("ws")
@@ -78,6 +115,7 @@ func InitWeb(
 		wsGroup.Use(middleware.WSMiddleware(cfg))
 		wsGroup.GET("", ws.HandleWS)
 	}
+	router.GET("ws/schema", ws.Schema)
 
 	return router
 }
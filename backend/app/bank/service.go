@@ -0,0 +1,101 @@
+package bank
+
+import (
+	"errors"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+type Repository interface {
+	GetQuestion(ID int) *entity.BankQuestion
+	SearchQuestions(ownerID uint, filter entity.BankFilter) []entity.BankQuestion
+	CreateQuestion(e *entity.BankQuestion) *entity.BankQuestion
+	UpdateQuestion(e *entity.BankQuestion) *entity.BankQuestion
+	DeleteQuestion(e *entity.BankQuestion)
+	GetUsage(e *entity.BankQuestion) []uint
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(bankRepo Repository) *Service {
+	return &Service{
+		repo: bankRepo,
+	}
+}
+
+// GetQuestion returns a question from userID's bank along with the games that use it.
+func (s Service) GetQuestion(ID int, userID uint) (*entity.BankQuestion, error) {
+	question := s.repo.GetQuestion(ID)
+	if question.ID == 0 {
+		return nil, errors.New("question not found")
+	}
+
+	if question.OwnerID != userID {
+		return nil, errors.New("you shall not pass! (not owner)")
+	}
+
+	question.UsedBy = s.repo.GetUsage(question)
+	return question, nil
+}
+
+func (s Service) SearchQuestions(userID uint, filter entity.BankFilter) (*entity.BankPage, error) {
+	if filter.Tag != "" {
+		tags, err := entity.NormalizeTags([]string{filter.Tag})
+		if err != nil {
+			return nil, err
+		}
+		filter.Tag = tags[0]
+	}
+
+	limit := filter.Limit
+	// Fetch one extra question to know whether there is another page.
+	filter.Limit = limit + 1
+	questions := s.repo.SearchQuestions(userID, filter)
+
+	page := &entity.BankPage{Questions: questions}
+	if len(questions) > limit {
+		page.Questions = questions[:limit]
+		page.NextCursor = page.Questions[limit-1].ID
+	}
+	if page.Questions == nil {
+		page.Questions = []entity.BankQuestion{}
+	}
+
+	return page, nil
+}
+
+func (s Service) CreateQuestion(body entity.CreateBankQuestion, ownerID uint) (*entity.BankQuestion, error) {
+	question, err := entity.NewBankQuestion(body, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateQuestion(question), nil
+}
+
+// UpdateQuestion edits a bank question. The games using it keep their copy and UsedBy tells the caller
+// which games could be updated.
+func (s Service) UpdateQuestion(ID int, body entity.CreateBankQuestion, userID uint) (*entity.BankQuestion, error) {
+	question, err := s.GetQuestion(ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := question.Apply(body); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateQuestion(question), nil
+}
+
+func (s Service) DeleteQuestion(ID int, userID uint) error {
+	question, err := s.GetQuestion(ID, userID)
+	if err != nil {
+		return err
+	}
+
+	s.repo.DeleteQuestion(question)
+	return nil
+}
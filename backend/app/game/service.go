@@ -1,9 +1,12 @@
 package game
 
 import (
+	"bytes"
 	"errors"
+	"io"
 
 	"github.com/ip-05/quizzus/entity"
+	"github.com/ip-05/quizzus/formats"
 )
 
 type Repository interface {
@@ -17,99 +20,448 @@ type Repository interface {
 	DeleteQuestion(ID int)
 
 	ToggleFavoriteGame(e *entity.FavoriteGame) bool
+
+	GetSessionStats(ID uint) entity.SessionStats
+	GetQuestionStats(ID uint) []entity.QuestionStats
+	GetWrongOptionStats(ID uint) []entity.OptionStats
+
+	ClaimRevision(ID uint, current, next int) bool
+	SetStatus(ID uint, status string)
+
+	CreateRevision(e *entity.GameRevision) *entity.GameRevision
+	GetRevision(gameID uint, number int) *entity.GameRevision
+	GetRevisions(gameID uint) []entity.GameRevision
+
+	SaveCollaborator(e *entity.Collaborator) *entity.Collaborator
+	DeleteCollaborator(gameID, userID uint)
+	UserExists(ID uint) bool
+}
+
+type BankService interface {
+	GetQuestion(ID int, userID uint) (*entity.BankQuestion, error)
 }
 
 type Service struct {
 	repo Repository
+	bank BankService
 }
 
-func NewService(gameRepo Repository) *Service {
+func NewService(gameRepo Repository, bankSvc BankService) *Service {
 	return &Service{
 		repo: gameRepo,
+		bank: bankSvc,
+	}
+}
+
+// fromBank returns q, or a copy of the bank question it refers to.
+func (s Service) fromBank(q entity.CreateQuestion, ownerID uint) (entity.CreateQuestion, error) {
+	if q.BankQuestionID == 0 {
+		return q, nil
 	}
+
+	question, err := s.bank.GetQuestion(int(q.BankQuestionID), ownerID)
+	if err != nil {
+		return q, err
+	}
+
+	return question.CreateQuestion(), nil
 }
 
 func (s Service) CreateGame(body entity.CreateGame, ownerID uint) (*entity.Game, error) {
+	for i, q := range body.Questions {
+		question, err := s.fromBank(q, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		body.Questions[i] = question
+	}
+
 	e, err := entity.NewGame(body, ownerID)
 	if err != nil {
 		return nil, err
 	}
 
-	game := s.repo.CreateGame(e)
+	return s.create(e, ownerID), nil
+}
+
+// create saves a new game along with its first revision.
+func (s Service) create(game *entity.Game, authorID uint) *entity.Game {
+	game = s.repo.CreateGame(game)
+	s.repo.CreateRevision(entity.NewRevision(game, authorID))
+	return game
+}
+
+// save saves changes to game as its next revision and deletes the questions it no longer has. before is the
+// game as it was loaded; if another change was saved since, nothing is and entity.ErrStaleRevision is returned.
+// Games from before revisions first get their content before the change recorded, so the change has
+// something to be compared with.
+func (s Service) save(game *entity.Game, before *entity.GameRevision, removed []uint, authorID uint) (*entity.Game, error) {
+	next := before.Number + 1
+	if before.Number == 0 {
+		next = 2
+	}
+
+	if !s.repo.ClaimRevision(game.ID, before.Number, next) {
+		return nil, entity.ErrStaleRevision
+	}
+
+	if before.Number == 0 {
+		before.Number = 1
+		s.repo.CreateRevision(before)
+	}
+
+	for _, questionID := range removed {
+		s.repo.DeleteQuestion(int(questionID))
+	}
+
+	game.Revision = next
+	game = s.repo.UpdateGame(int(game.ID), game.InviteCode, game)
+	s.repo.CreateRevision(entity.NewRevision(game, authorID))
 	return game, nil
 }
 
-func (s Service) UpdateGame(body entity.UpdateGame, ID int, code string, ownerID uint) (*entity.Game, error) {
+// ImportGame builds a game from a document in format, taking its settings from body unless the document
+// carries its own, in which case body can only rename it. Questions that could not be read fail the import
+// unless it is a dry run, which only previews the game without saving it.
+func (s Service) ImportGame(format string, r io.Reader, body entity.CreateGame, ownerID uint, dryRun bool) (*entity.ImportResult, error) {
+	parsed, err := formats.Parse(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Settings != nil {
+		settings := *parsed.Settings
+		if body.Topic != "" {
+			settings.Topic = body.Topic
+		}
+		body = settings
+	}
+	body.Questions = parsed.Questions
+	if body.Topic == "" {
+		body.Topic = parsed.Title
+	}
+
+	result := &entity.ImportResult{Errors: parsed.Errors, Warnings: parsed.Warnings, DryRun: dryRun}
+
+	game, err := entity.NewGame(body, ownerID)
+	if err != nil {
+		if !dryRun {
+			return result, err
+		}
+		result.Errors = append(result.Errors, entity.ImportError{Message: err.Error()})
+		return result, nil
+	}
+	result.Game = game
+
+	if dryRun {
+		return result, nil
+	}
+	if len(result.Errors) > 0 {
+		return result, errors.New("some questions could not be imported")
+	}
+
+	result.Game = s.create(game, ownerID)
+	return result, nil
+}
+
+// UpdateGame saves body over the game if it was made from the game's current revision. Questions are matched
+// to the game's by ID and edited in place when they keep their number of options; any other question is added
+// as a new one and the game's questions missing from body are deleted. A stale revision returns the current
+// game along with entity.ErrStaleRevision.
+func (s Service) UpdateGame(body entity.UpdateGame, ID int, code string, userID uint) (*entity.Game, error) {
 	game, err := s.GetGame(ID, code)
 	if err != nil {
 		return nil, err
 	}
 
-	if ownerID != game.Owner {
-		return nil, errors.New("you shall not pass! (not owner)")
+	if !game.Can(userID, entity.ActionEdit) {
+		return nil, errors.New("you shall not pass! (not an editor)")
+	}
+
+	if body.Revision != game.Revision {
+		return game, entity.ErrStaleRevision
 	}
 
+	before := entity.NewRevision(game, game.Owner)
+
 	game.Topic = body.Topic
 	game.RoundTime = body.RoundTime
 	game.Points = body.Points
+	if body.ShuffleQuestions != nil {
+		game.ShuffleQuestions = *body.ShuffleQuestions
+	}
+	if body.ShuffleOptions != nil {
+		game.ShuffleOptions = *body.ShuffleOptions
+	}
+	if body.PoolSize != nil {
+		game.PoolSize = *body.PoolSize
+	}
 
-	ids := make(map[uint]int)
-	// assign each question id from existing game a 1
-	for _, y := range game.Questions {
-		ids[y.ID] += 1
+	current := make(map[uint]*entity.Question)
+	for _, q := range game.Questions {
+		current[q.ID] = q
 	}
 
-	for i, x := range body.Questions {
-		// assign each question id from update a +1
-		ids[x.ID] += 1
+	kept := make(map[uint]bool)
+	var questions []*entity.Question
+	for _, x := range body.Questions {
+		existing, ok := current[x.ID]
+		if ok && !kept[x.ID] && len(existing.Options) == len(x.Options) {
+			existing.Name = x.Name
+			existing.Explanation = x.Explanation
+			existing.BankQuestionID = x.BankQuestionID
+			for j, o := range x.Options {
+				existing.Options[j].Name = o.Name
+				existing.Options[j].Correct = o.Correct
+			}
 
-		// if question ids match => assign new values to question and it's options
-		if ids[x.ID] == 2 {
-			game.Questions[i].Name = x.Name
+			kept[x.ID] = true
+			questions = append(questions, existing)
+			continue
+		}
 
-			for j := 0; j < 4; j++ {
-				game.Questions[i].Options[j].Name = x.Options[j].Name
-				game.Questions[i].Options[j].Correct = x.Options[j].Correct
-			}
-		} else {
-			// if question ids don't match (question doesn't already exist) => add a new question to game
-			question := entity.Question{
-				Name: x.Name,
-			}
+		question, err := s.newQuestion(x, userID)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, question)
+	}
 
-			for i := 0; i < 4; i++ {
-				question.Options = append(question.Options, &entity.Option{Name: x.Options[i].Name, Correct: x.Options[i].Correct})
-			}
+	var removed []uint
+	for _, q := range game.Questions {
+		if !kept[q.ID] {
+			removed = append(removed, q.ID)
+		}
+	}
+	game.Questions = questions
 
-			err = question.Validate()
-			if err != nil {
-				return nil, err
-			}
+	err = game.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.save(game, before, removed, userID)
+	if errors.Is(err, entity.ErrStaleRevision) {
+		return s.reload(ID, code, err)
+	}
+	return saved, err
+}
+
+// newQuestion makes a question added by an update, from the bank when it refers to a bank question.
+func (s Service) newQuestion(x entity.UpdateQuestion, userID uint) (*entity.Question, error) {
+	body := entity.CreateQuestion{
+		Name:           x.Name,
+		Explanation:    x.Explanation,
+		BankQuestionID: x.BankQuestionID,
+	}
+	for _, o := range x.Options {
+		body.Options = append(body.Options, entity.CreateOption{Name: o.Name, Correct: o.Correct})
+	}
+
+	body, err := s.fromBank(body, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewQuestion(body)
+}
+
+// propagateAttempts is how many times a bank question is copied into a game that keeps changing meanwhile.
+const propagateAttempts = 3
+
+// PropagateQuestion copies a bank question into userID's games made from it, saving each as a new revision.
+func (s Service) PropagateQuestion(bankID int, userID uint) error {
+	question, err := s.bank.GetQuestion(bankID, userID)
+	if err != nil {
+		return err
+	}
 
-			game.Questions = append(game.Questions, &question)
-			ids[x.ID] += 1
+	for _, gameID := range question.UsedBy {
+		if err := s.propagate(int(gameID), question, userID); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// if questions isn't in update body => delete it from the game
-	for i, v := range ids {
-		if v == 1 {
-			for j, v2 := range game.Questions {
-				if v2.ID == i {
-					game.Questions = append(game.Questions[:j], game.Questions[j+1:]...)
-				}
-			}
-			s.repo.DeleteQuestion(int(i))
+// propagate copies question into one game, loading the game again if another change was saved first.
+func (s Service) propagate(gameID int, question *entity.BankQuestion, userID uint) error {
+	for i := 0; i < propagateAttempts; i++ {
+		game, err := s.GetGame(gameID, "")
+		if err != nil {
+			return err
+		}
+
+		before := entity.NewRevision(game, game.Owner)
+		removed := game.ApplyBankQuestion(question)
+		if err := game.Validate(); err != nil {
+			return err
+		}
+
+		_, err = s.save(game, before, removed, userID)
+		if !errors.Is(err, entity.ErrStaleRevision) {
+			return err
 		}
 	}
+	return entity.ErrStaleRevision
+}
 
-	err = game.Validate()
+// reload returns the game as it is now along with err, for changes refused because it had moved on.
+func (s Service) reload(ID int, code string, err error) (*entity.Game, error) {
+	game, getErr := s.GetGame(ID, code)
+	if getErr != nil {
+		return nil, getErr
+	}
+	return game, err
+}
+
+// PublishGame makes a draft hostable and listable once it passes full validation.
+func (s Service) PublishGame(ID int, code string, userID uint) (*entity.Game, error) {
+	game, err := s.GetGame(ID, code)
 	if err != nil {
 		return nil, err
 	}
 
-	e := s.repo.UpdateGame(ID, code, game)
-	return e, nil
+	if !game.Can(userID, entity.ActionEdit) {
+		return nil, errors.New("you shall not pass! (not an editor)")
+	}
+
+	if game.Published() {
+		return nil, errors.New("game is already published")
+	}
+
+	if err := game.Publish(); err != nil {
+		return nil, err
+	}
+
+	s.repo.SetStatus(game.ID, game.Status)
+	return game, nil
+}
+
+// GetRevisions lists the game's revisions, each with what changed since the one before it.
+func (s Service) GetRevisions(ID int, code string, userID uint) ([]entity.GameRevision, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionView) {
+		return nil, errors.New("you shall not pass! (not a collaborator)")
+	}
+
+	revisions := s.repo.GetRevisions(game.ID)
+	for i := range revisions {
+		revisions[i].Changes = []entity.RevisionChange{}
+		if i > 0 && revisions[i-1].Content != nil && revisions[i].Content != nil {
+			revisions[i].Changes = entity.DiffRevisions(revisions[i-1].Content, revisions[i].Content)
+		}
+	}
+	for i := range revisions {
+		revisions[i].Content = nil
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns one revision of the game with what changed since compare, or since the revision
+// before it when compare is zero.
+func (s Service) GetRevision(ID int, code string, number, compare int, userID uint) (*entity.GameRevision, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionView) {
+		return nil, errors.New("you shall not pass! (not a collaborator)")
+	}
+
+	revision := s.repo.GetRevision(game.ID, number)
+	if revision.ID == 0 || revision.Content == nil {
+		return nil, errors.New("revision not found")
+	}
+
+	if compare == 0 {
+		compare = number - 1
+	}
+	revision.Changes = []entity.RevisionChange{}
+	if compare > 0 {
+		other := s.repo.GetRevision(game.ID, compare)
+		if other.ID == 0 || other.Content == nil {
+			return nil, errors.New("revision to compare with not found")
+		}
+		revision.Changes = entity.DiffRevisions(other.Content, revision.Content)
+	}
+
+	return revision, nil
+}
+
+// RollbackGame brings back the content of an earlier revision as the game's next revision, leaving the
+// history in between untouched.
+func (s Service) RollbackGame(ID int, code string, number int, userID uint) (*entity.Game, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionEdit) {
+		return nil, errors.New("you shall not pass! (not an editor)")
+	}
+
+	revision := s.repo.GetRevision(game.ID, number)
+	if revision.ID == 0 || revision.Content == nil {
+		return nil, errors.New("revision not found")
+	}
+
+	before := entity.NewRevision(game, game.Owner)
+
+	removed := revision.Content.Restore(game)
+	if err := game.Validate(); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.save(game, before, removed, userID)
+	if errors.Is(err, entity.ErrStaleRevision) {
+		return s.reload(ID, code, err)
+	}
+	return saved, err
+}
+
+// CloneGame copies the game into a new one owned by userID. Anyone can clone published public games.
+func (s Service) CloneGame(ID int, code string, userID uint) (*entity.Game, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if (!game.Public || !game.Published()) && !game.Can(userID, entity.ActionView) {
+		return nil, errors.New("you shall not pass! (private quiz)")
+	}
+
+	clone, err := game.Clone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.create(clone, userID), nil
+}
+
+// ExportGame writes the game to a document in format. It shows the answers, so it takes a role that can view them.
+func (s Service) ExportGame(ID int, code string, userID uint, format string) ([]byte, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionView) {
+		return nil, errors.New("you shall not pass! (not a collaborator)")
+	}
+
+	var buf bytes.Buffer
+	if err := formats.Export(format, &buf, game); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
 
 func (s Service) DeleteGame(ID int, code string, userID uint) error {
@@ -118,7 +470,7 @@ func (s Service) DeleteGame(ID int, code string, userID uint) error {
 		return err
 	}
 
-	if userID != game.Owner {
+	if !game.Can(userID, entity.ActionManage) {
 		return errors.New("you shall not pass! (not owner)")
 	}
 
@@ -126,6 +478,61 @@ func (s Service) DeleteGame(ID int, code string, userID uint) error {
 	return nil
 }
 
+func (s Service) GetCollaborators(ID int, code string, userID uint) ([]*entity.Collaborator, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionView) {
+		return nil, errors.New("you shall not pass! (not a collaborator)")
+	}
+
+	return game.Collaborators, nil
+}
+
+// AddCollaborator gives a user a role on the game, replacing the role they had.
+func (s Service) AddCollaborator(ID int, code string, body entity.CreateCollaborator, userID uint) (*entity.Collaborator, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionManage) {
+		return nil, errors.New("you shall not pass! (not owner)")
+	}
+
+	collaborator, err := entity.NewCollaborator(body, game)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.repo.UserExists(collaborator.UserID) {
+		return nil, errors.New("user not found")
+	}
+
+	return s.repo.SaveCollaborator(collaborator), nil
+}
+
+// RemoveCollaborator takes away a collaborator's role. Collaborators can also remove themselves.
+func (s Service) RemoveCollaborator(ID int, code string, collaboratorID, userID uint) error {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return err
+	}
+
+	if collaboratorID != userID && !game.Can(userID, entity.ActionManage) {
+		return errors.New("you shall not pass! (not owner)")
+	}
+
+	if game.Role(collaboratorID) == "" || collaboratorID == game.Owner {
+		return errors.New("collaborator not found")
+	}
+
+	s.repo.DeleteCollaborator(game.ID, collaboratorID)
+	return nil
+}
+
 func (s Service) GetGame(ID int, code string) (*entity.Game, error) {
 	e := s.repo.GetGame(ID, code)
 
@@ -152,6 +559,23 @@ func (s Service) GetFavoriteGames(user int) (*[]entity.Game, error) {
 	return games, nil
 }
 
+func (s Service) GetAnalytics(ID int, code string, userID uint) (*entity.GameAnalytics, error) {
+	game, err := s.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Can(userID, entity.ActionView) {
+		return nil, errors.New("you shall not pass! (not a collaborator)")
+	}
+
+	sessions := s.repo.GetSessionStats(game.ID)
+	questions := s.repo.GetQuestionStats(game.ID)
+	wrong := s.repo.GetWrongOptionStats(game.ID)
+
+	return entity.NewGameAnalytics(game, sessions, questions, wrong), nil
+}
+
 func (s Service) Favorite(ID int, userID int) bool {
 	favorite := &entity.FavoriteGame{
 		GameID: uint(ID),
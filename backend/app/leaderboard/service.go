@@ -0,0 +1,137 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+// cacheTTL is how long an aggregated leaderboard is served before it is computed again.
+const cacheTTL = 5 * time.Minute
+
+type Repository interface {
+	GetStandings(query entity.LeaderboardQuery, from time.Time) []entity.PlayerStanding
+	GetRatingStandings(limit int) []entity.RatingStanding
+}
+
+type GameService interface {
+	GetGame(ID int, code string) (*entity.Game, error)
+}
+
+type cached struct {
+	standings any
+	expires   time.Time
+}
+
+type Service struct {
+	repo  Repository
+	games GameService
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+func NewService(leaderboardRepo Repository, gameSvc GameService) *Service {
+	return &Service{
+		repo:  leaderboardRepo,
+		games: gameSvc,
+		cache: make(map[string]cached),
+	}
+}
+
+// GetGlobal ranks players across all public quizzes.
+func (s *Service) GetGlobal(query entity.LeaderboardQuery) ([]entity.PlayerStanding, error) {
+	query.GameID = 0
+	if query.Sort == "" {
+		query.Sort = entity.SortPoints
+	}
+
+	return s.standings(query)
+}
+
+// GetGame ranks players of a single quiz by their high score. Private quizzes are only visible to their owner
+// and collaborators.
+func (s *Service) GetGame(ID int, code string, userID uint, query entity.LeaderboardQuery) ([]entity.PlayerStanding, error) {
+	game, err := s.games.GetGame(ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !game.Public && game.Role(userID) == "" {
+		return nil, errors.New("you shall not pass! (private quiz)")
+	}
+
+	query.GameID = game.ID
+	if query.Sort == "" {
+		query.Sort = entity.SortBest
+	}
+
+	return s.standings(query)
+}
+
+func (s *Service) standings(query entity.LeaderboardQuery) ([]entity.PlayerStanding, error) {
+	if query.Sort != entity.SortPoints && query.Sort != entity.SortBest && query.Sort != entity.SortWinRate {
+		return nil, errors.New("sort must be points, best or win_rate")
+	}
+
+	now := time.Now()
+	from, err := entity.WindowStart(query.Window, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Windows are calendar aligned, so the start is part of the key and a new week gets a fresh entry.
+	key := fmt.Sprintf("%d:%s:%s:%d:%d", query.GameID, query.Window, query.Sort, query.Limit, from.Unix())
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.standings.([]entity.PlayerStanding), nil
+	}
+
+	standings := entity.RankStandings(s.repo.GetStandings(query, from), query.Sort)
+	if standings == nil {
+		standings = []entity.PlayerStanding{}
+	}
+
+	s.store(key, standings, now)
+	return standings, nil
+}
+
+// GetRatings ranks players by skill rating, once they have played enough rated games.
+func (s *Service) GetRatings(limit int) []entity.RatingStanding {
+	now := time.Now()
+	key := fmt.Sprintf("ratings:%d", limit)
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.standings.([]entity.RatingStanding)
+	}
+
+	standings := entity.RankRatings(s.repo.GetRatingStandings(limit))
+	if standings == nil {
+		standings = []entity.RatingStanding{}
+	}
+
+	s.store(key, standings, now)
+	return standings
+}
+
+// store caches standings under key and drops whatever has expired.
+func (s *Service) store(key string, standings any, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, e := range s.cache {
+		if now.After(e.expires) {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = cached{standings: standings, expires: now.Add(cacheTTL)}
+}
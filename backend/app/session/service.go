@@ -1,6 +1,7 @@
 package session
 
 import (
+	"errors"
 	"time"
 
 	"github.com/ip-05/quizzus/entity"
@@ -9,10 +10,34 @@ import (
 type Repository interface {
 	CreateSession(e *entity.GameSession) *entity.GameSession
 	EndSession(e *entity.GameSession) *entity.GameSession
-	GetSessions(userID, limit int) *[]entity.GameSession
+	GetSessions(userID int, filter entity.SessionFilter) []entity.GameSession
 	GetSession(ID, userID int) *entity.GameSession
+	GetLeaderboards(instIDs []uint) map[uint][]entity.Leaderboard
+	GetHosts(instIDs []uint) map[uint]uint
+	CreateAnswers(e []*entity.Answer)
+
+	CreateInstance(e *entity.GameInstance) *entity.GameInstance
+	EndInstance(e *entity.GameInstance) *entity.GameInstance
+	GetInstance(ID int) *entity.GameInstance
+	GetRevision(gameID uint, number int) *entity.GameRevision
+	GetLeaderboard(instID uint) []entity.Leaderboard
+	GetInstanceAnswers(instID uint) []entity.Answer
+
+	GetProgress(userIDs []uint) map[uint]entity.Progress
+	GetRecentAnswers(userIDs []uint, limit int) map[uint][]entity.Answer
+	GetAchievements(userIDs []uint) map[uint][]entity.Achievement
+	CreateAchievements(e []*entity.Achievement)
+
+	GetAccuracy(userID uint) (int64, int64)
+	GetFavouriteTopics(userID uint, limit int, publicOnly bool) []entity.TopicStats
 }
 
+const (
+	// favouriteTopics and recentSessions are how many of each a profile shows.
+	favouriteTopics = 3
+	recentSessions  = 5
+)
+
 type Service struct {
 	repo Repository
 }
@@ -30,20 +55,199 @@ func (s Service) NewSession(ID, userID, instID int) uint {
 }
 
 func (s Service) EndSession(ID, userID, instID, questions, players int, points float64) uint {
-	newSession := entity.NewSession(uint(ID), uint(userID), uint(instID))
-	newSession.Questions = questions
-	newSession.Players = players
-	newSession.Points = points
-	newSession.EndedAt = time.Now()
+	newSession := &entity.GameSession{
+		GameID:     uint(ID),
+		UserID:     uint(userID),
+		InstanceID: uint(instID),
+		Questions:  questions,
+		Players:    players,
+		Points:     points,
+		EndedAt:    time.Now(),
+	}
 
 	session := s.repo.EndSession(newSession)
 	return session.UserID
 }
 
-func (s Service) GetSessions(userID, limit int) *[]entity.GameSession {
-	return s.repo.GetSessions(userID, limit)
+func (s Service) NewInstance(ID, revision, hostID, instID int) uint {
+	instance := s.repo.CreateInstance(entity.NewInstance(uint(ID), revision, uint(hostID), uint(instID)))
+	return instance.ID
+}
+
+func (s Service) EndInstance(instID, questions, players int) uint {
+	instance := &entity.GameInstance{
+		ID:        uint(instID),
+		Questions: questions,
+		Players:   players,
+		EndedAt:   time.Now(),
+	}
+
+	instance = s.repo.EndInstance(instance)
+	return instance.ID
+}
+
+// getInstance returns the instance with its game as it was when the instance played it.
+func (s Service) getInstance(instID int) *entity.GameInstance {
+	instance := s.repo.GetInstance(instID)
+	if instance.ID == 0 || instance.Revision == 0 {
+		return instance
+	}
+
+	if revision := s.repo.GetRevision(instance.GameID, instance.Revision); revision.Content != nil {
+		revision.Content.Apply(&instance.Game)
+	}
+	return instance
+}
+
+func (s Service) GetResults(instID int, userID uint) (*entity.InstanceResults, error) {
+	instance := s.getInstance(instID)
+	if instance.ID == 0 {
+		return nil, errors.New("instance not found")
+	}
+
+	if instance.HostID != userID {
+		return nil, errors.New("you shall not pass! (not host)")
+	}
+
+	leaderboard := s.repo.GetLeaderboard(instance.ID)
+	answers := s.repo.GetInstanceAnswers(instance.ID)
+
+	return entity.NewInstanceResults(instance, leaderboard, answers), nil
+}
+
+// GetReview returns userID's review of a finished instance they took part in. Until the instance has ended
+// it is refused, so answers cannot leak to players still in the game.
+func (s Service) GetReview(instID int, userID uint) (*entity.Review, error) {
+	instance := s.getInstance(instID)
+	if instance.ID == 0 {
+		return nil, errors.New("instance not found")
+	}
+
+	if instance.EndedAt.Before(instance.StartedAt) {
+		return nil, errors.New("game is still in progress")
+	}
+
+	leaderboard := s.repo.GetLeaderboard(instance.ID)
+
+	played := false
+	for _, entry := range leaderboard {
+		if entry.UserID == userID {
+			played = true
+		}
+	}
+	if !played {
+		return nil, errors.New("you shall not pass! (not a player)")
+	}
+
+	answers := s.repo.GetInstanceAnswers(instance.ID)
+	return entity.NewReview(instance, leaderboard, answers, userID), nil
+}
+
+func (s Service) SaveAnswers(answers []*entity.Answer) {
+	s.repo.CreateAnswers(answers)
+}
+
+// UnlockAchievements evaluates the achievement rules for everyone who took part in a finished instance,
+// host included, and returns what each of them unlocked.
+func (s Service) UnlockAchievements(instID int) map[uint][]*entity.Achievement {
+	instance := s.repo.GetInstance(instID)
+	if instance.ID == 0 {
+		return nil
+	}
+
+	userIDs := []uint{instance.HostID}
+	for _, entry := range s.repo.GetLeaderboard(instance.ID) {
+		if entry.UserID != instance.HostID {
+			userIDs = append(userIDs, entry.UserID)
+		}
+	}
+
+	played := make(map[uint][]entity.Answer)
+	for _, answer := range s.repo.GetInstanceAnswers(instance.ID) {
+		played[answer.UserID] = append(played[answer.UserID], answer)
+	}
+
+	progress := s.repo.GetProgress(userIDs)
+	recent := s.repo.GetRecentAnswers(userIDs, entity.StreakLength)
+	unlocked := s.repo.GetAchievements(userIDs)
+
+	achievements := make(map[uint][]*entity.Achievement)
+	var created []*entity.Achievement
+	for _, userID := range userIDs {
+		p := progress[userID]
+		p.Streak = entity.Streak(recent[userID])
+		p.Perfect = entity.PerfectGame(played[userID], instance.Questions)
+
+		if unlocks := entity.NewAchievements(userID, instance.ID, p, unlocked[userID]); len(unlocks) > 0 {
+			achievements[userID] = unlocks
+			created = append(created, unlocks...)
+		}
+	}
+
+	s.repo.CreateAchievements(created)
+	return achievements
+}
+
+// GetStats builds the stats block of a user's profile as viewerID sees it. Other users only see the topics
+// and sessions of public quizzes.
+func (s Service) GetStats(userID, viewerID uint) *entity.ProfileStats {
+	publicOnly := userID != viewerID
+
+	progress := s.repo.GetProgress([]uint{userID})[userID]
+	answers, correct := s.repo.GetAccuracy(userID)
+	topics := s.repo.GetFavouriteTopics(userID, favouriteTopics, publicOnly)
+	page := s.GetSessions(int(userID), entity.SessionFilter{Limit: recentSessions, PublicOnly: publicOnly})
+
+	return entity.NewProfileStats(progress, answers, correct, topics, page.Sessions)
+}
+
+func (s Service) GetSessions(userID int, filter entity.SessionFilter) *entity.SessionPage {
+	limit := filter.Limit
+	// Fetch one extra session to know whether there is another page.
+	filter.Limit = limit + 1
+	sessions := s.repo.GetSessions(userID, filter)
+
+	page := &entity.SessionPage{Sessions: sessions}
+	if len(sessions) > limit {
+		page.Sessions = sessions[:limit]
+		page.NextCursor = page.Sessions[limit-1].ID
+	}
+
+	s.setLeaderboards(page.Sessions)
+	return page
 }
 
 func (s Service) GetSession(ID, userID int) *entity.GameSession {
-	return s.repo.GetSession(ID, userID)
+	session := s.repo.GetSession(ID, userID)
+	if session.ID == 0 {
+		return session
+	}
+
+	sessions := []entity.GameSession{*session}
+	s.setLeaderboards(sessions)
+	return &sessions[0]
+}
+
+// setLeaderboards attaches each session's instance leaderboard. Sessions played before instances
+// were recorded have no known host, so the game owner is assumed to have hosted them.
+func (s Service) setLeaderboards(sessions []entity.GameSession) {
+	if len(sessions) == 0 {
+		return
+	}
+
+	instIDs := make([]uint, len(sessions))
+	for i, session := range sessions {
+		instIDs[i] = session.InstanceID
+	}
+
+	leaderboards := s.repo.GetLeaderboards(instIDs)
+	hosts := s.repo.GetHosts(instIDs)
+
+	for i := range sessions {
+		hostID, ok := hosts[sessions[i].InstanceID]
+		if !ok {
+			hostID = sessions[i].Game.Owner
+		}
+		sessions[i].SetLeaderboard(leaderboards[sessions[i].InstanceID], hostID)
+	}
 }
@@ -1,6 +1,14 @@
 package user
 
-import "github.com/ip-05/quizzus/entity"
+import (
+	"sort"
+
+	"github.com/ip-05/quizzus/config"
+	"github.com/ip-05/quizzus/entity"
+)
+
+// ratingHistory is how many recent rating changes a profile shows.
+const ratingHistory = 50
 
 type Repository interface {
 	GetUserById(ID uint) *entity.User
@@ -10,15 +18,39 @@ type Repository interface {
 	CreateUser(e *entity.User) *entity.User
 	UpdateUser(e *entity.User) *entity.User
 	DeleteUser(e *entity.User)
+
+	GetRatings(IDs []uint) map[uint]float64
+	SaveRatings(changes []*entity.RatingChange)
+	GetRatingHistory(userID uint, limit int) []entity.RatingChange
+	GetAchievements(userID uint) []entity.Achievement
+	GetXP(IDs []uint) map[uint]int64
+	AddXP(gains map[uint]int64)
+}
+
+type StatsService interface {
+	GetStats(userID, viewerID uint) *entity.ProfileStats
 }
 
 type Service struct {
-	repo Repository
+	repo  Repository
+	stats StatsService
+	curve entity.LevelCurve
+	xp    entity.XPRules
 }
 
-func NewService(userRepo Repository) *Service {
+func NewService(cfg *config.Config, userRepo Repository, statsSvc StatsService) *Service {
 	return &Service{
-		repo: userRepo,
+		repo:  userRepo,
+		stats: statsSvc,
+		curve: entity.LevelCurve{
+			Base:     cfg.XP.LevelBase,
+			Exponent: cfg.XP.LevelExponent,
+		},
+		xp: entity.XPRules{
+			Participation: cfg.XP.Participation,
+			Correct:       cfg.XP.Correct,
+			Placement:     cfg.XP.Placement,
+		},
 	}
 }
 
@@ -37,12 +69,16 @@ func (s Service) UpdateUser(ID uint, body entity.UpdateUser) (*entity.User, erro
 
 	user.Name = body.Name
 	user.Picture = body.Picture
+	if body.PrivateStats != nil {
+		user.PrivateStats = *body.PrivateStats
+	}
 
 	if err := user.Validate(); err != nil {
 		return nil, err
 	}
 
 	s.repo.UpdateUser(user)
+	user.Level = s.curve.Progress(user.XP)
 	return user, nil
 }
 
@@ -55,6 +91,74 @@ func (s Service) GetUserById(ID uint) *entity.User {
 	return s.repo.GetUserById(ID)
 }
 
+// GetProfile returns the user with their level, stats, achievements and recent rating history, oldest
+// first. Users with private stats only show their private profile to others, and others only see the
+// sessions they played in public quizzes.
+func (s Service) GetProfile(ID uint, viewerID uint) any {
+	user := s.repo.GetUserById(ID)
+	if user == nil {
+		return nil
+	}
+
+	if !user.ShowsStatsTo(viewerID) {
+		return user.PrivateProfile()
+	}
+
+	user.Level = s.curve.Progress(user.XP)
+
+	history := s.repo.GetRatingHistory(ID, ratingHistory)
+	sort.Slice(history, func(i, j int) bool { return history[i].ID < history[j].ID })
+	user.RatingHistory = history
+	user.Achievements = entity.DescribeAchievements(s.repo.GetAchievements(ID))
+	user.Stats = s.stats.GetStats(ID, viewerID)
+
+	return user
+}
+
+// UpdateRatings rates a finished game from its ranked leaderboard and returns each player's change.
+func (s Service) UpdateRatings(instID uint, leaderboard []entity.Leaderboard) map[uint]*entity.RatingChange {
+	IDs := make([]uint, 0, len(leaderboard))
+	for _, entry := range leaderboard {
+		IDs = append(IDs, entry.UserID)
+	}
+
+	changes := entity.NewRatingChanges(instID, s.repo.GetRatings(IDs), leaderboard)
+	if len(changes) == 0 {
+		return nil
+	}
+	s.repo.SaveRatings(changes)
+
+	byUser := make(map[uint]*entity.RatingChange, len(changes))
+	for _, change := range changes {
+		byUser[change.UserID] = change
+	}
+	return byUser
+}
+
+// AwardXP gives each ranked player XP for finishing the game, for their correct answers and for their
+// placement, and returns what each of them gained.
+func (s Service) AwardXP(leaderboard []entity.Leaderboard, correct map[uint]int) map[uint]*entity.XPGain {
+	if len(leaderboard) == 0 {
+		return nil
+	}
+
+	IDs := make([]uint, 0, len(leaderboard))
+	for _, entry := range leaderboard {
+		IDs = append(IDs, entry.UserID)
+	}
+	current := s.repo.GetXP(IDs)
+
+	earned := make(map[uint]int64, len(leaderboard))
+	gains := make(map[uint]*entity.XPGain, len(leaderboard))
+	for _, entry := range leaderboard {
+		earned[entry.UserID] = s.xp.Earned(correct[entry.UserID], entry.Rank)
+		gains[entry.UserID] = entity.NewXPGain(s.curve, current[entry.UserID], earned[entry.UserID])
+	}
+
+	s.repo.AddXP(earned)
+	return gains
+}
+
 func (s Service) GetUserByProvider(ID string, provider string) *entity.User {
 	switch provider {
 	case "google":
@@ -5,13 +5,17 @@ import (
 	"net/http"
 
 	"github.com/ip-05/quizzus/app/auth"
+	"github.com/ip-05/quizzus/app/bank"
+	"github.com/ip-05/quizzus/app/leaderboard"
 	"github.com/ip-05/quizzus/app/session"
 	"github.com/ip-05/quizzus/app/user"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
 	"github.com/ip-05/quizzus/repo"
+	bankRepo "github.com/ip-05/quizzus/repo/bank"
 	gameRepo "github.com/ip-05/quizzus/repo/game"
+	leaderboardRepo "github.com/ip-05/quizzus/repo/leaderboard"
 	sessionRepo "github.com/ip-05/quizzus/repo/session"
 	userRepo "github.com/ip-05/quizzus/repo/user"
 
@@ -40,15 +44,19 @@ func main() {
 	gameRepo := gameRepo.NewRepository(db)
 	userRepo := userRepo.NewRepository(db)
 	sessionRepo := sessionRepo.NewRepository(db)
+	leaderboardRepo := leaderboardRepo.NewRepository(db)
+	bankRepo := bankRepo.NewRepository(db)
 
 	// Business logic layer
-	gameService := game.NewService(gameRepo)
-	userService := user.NewService(userRepo)
-	authService := auth.NewService(cfg, gcfg, userService, &http.Client{})
+	bankService := bank.NewService(bankRepo)
+	gameService := game.NewService(gameRepo, bankService)
 	sessionService := session.NewSessionService(sessionRepo)
+	userService := user.NewService(cfg, userRepo, sessionService)
+	authService := auth.NewService(cfg, gcfg, userService, &http.Client{})
+	leaderboardService := leaderboard.NewService(leaderboardRepo, gameService)
 
 	// Presentation layer
-	r := api.InitWeb(cfg, gcfg, gameService, authService, userService, sessionService)
+	r := api.InitWeb(cfg, gcfg, gameService, authService, userService, sessionService, leaderboardService, bankService)
 
 	r.Run(fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port))
 }
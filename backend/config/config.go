@@ -12,6 +12,8 @@ type Config struct {
 	Secrets  *SecretConfig
 	Frontend *FrontendConfig
 	Database *DatabaseConfig
+	Socket   *SocketConfig
+	XP       *XPConfig
 }
 
 type ServerConfig struct {
@@ -44,6 +46,23 @@ type DatabaseConfig struct {
 	Secure   bool
 }
 
+type SocketConfig struct {
+	PingInterval int64
+	PingTimeout  int64
+	LobbyTimeout int64
+	SendQueue    int64
+	WriteTimeout int64
+	AnswerGrace  int64
+}
+
+type XPConfig struct {
+	Participation int64
+	Correct       int64
+	Placement     []int64
+	LevelBase     int64
+	LevelExponent float64
+}
+
 var config *Config
 
 func Init(name string, path string) *Config {
@@ -51,6 +70,19 @@ func Init(name string, path string) *Config {
 	viper.SetConfigName(name)
 	viper.SetConfigType("toml")
 
+	viper.SetDefault("socket.ping_interval", int64(15))
+	viper.SetDefault("socket.ping_timeout", int64(10))
+	viper.SetDefault("socket.lobby_timeout", int64(1800))
+	viper.SetDefault("socket.send_queue", int64(64))
+	viper.SetDefault("socket.write_timeout", int64(5))
+	viper.SetDefault("socket.answer_grace", int64(500))
+
+	viper.SetDefault("xp.participation", int64(10))
+	viper.SetDefault("xp.correct", int64(5))
+	viper.SetDefault("xp.placement", []int64{30, 20, 10})
+	viper.SetDefault("xp.level_base", int64(100))
+	viper.SetDefault("xp.level_exponent", float64(1.5))
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		panic(fmt.Sprintf("Error while reading config: %s", err.Error()))
@@ -86,12 +118,36 @@ func Init(name string, path string) *Config {
 		Secure:   viper.Get("db.secure").(bool),
 	}
 
+	socketConfig := SocketConfig{
+		PingInterval: viper.Get("socket.ping_interval").(int64),
+		PingTimeout:  viper.Get("socket.ping_timeout").(int64),
+		LobbyTimeout: viper.Get("socket.lobby_timeout").(int64),
+		SendQueue:    viper.Get("socket.send_queue").(int64),
+		WriteTimeout: viper.Get("socket.write_timeout").(int64),
+		AnswerGrace:  viper.Get("socket.answer_grace").(int64),
+	}
+
+	var placement []int64
+	for _, xp := range viper.GetIntSlice("xp.placement") {
+		placement = append(placement, int64(xp))
+	}
+
+	xpConfig := XPConfig{
+		Participation: viper.Get("xp.participation").(int64),
+		Correct:       viper.Get("xp.correct").(int64),
+		Placement:     placement,
+		LevelBase:     viper.Get("xp.level_base").(int64),
+		LevelExponent: viper.GetFloat64("xp.level_exponent"),
+	}
+
 	config = &Config{
 		Server:   &serverConfig,
 		Google:   &googleConfig,
 		Secrets:  &secretConfig,
 		Frontend: &frontendConfig,
 		Database: &dbConfig,
+		Socket:   &socketConfig,
+		XP:       &xpConfig,
 	}
 
 	return config
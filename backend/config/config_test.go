@@ -39,6 +39,23 @@ func TestInitConfig(t *testing.T) {
 		assert.Equal(t, false, cfg.Database.Secure, "should be equal")
 	})
 
+	t.Run("TestConfigSocket", func(t *testing.T) {
+		assert.Equal(t, int64(15), cfg.Socket.PingInterval, "should be equal")
+		assert.Equal(t, int64(10), cfg.Socket.PingTimeout, "should be equal")
+		assert.Equal(t, int64(1800), cfg.Socket.LobbyTimeout, "should be equal")
+		assert.Equal(t, int64(64), cfg.Socket.SendQueue, "should be equal")
+		assert.Equal(t, int64(5), cfg.Socket.WriteTimeout, "should be equal")
+		assert.Equal(t, int64(500), cfg.Socket.AnswerGrace, "should be equal")
+	})
+
+	t.Run("TestConfigXP", func(t *testing.T) {
+		assert.Equal(t, int64(10), cfg.XP.Participation, "should be equal")
+		assert.Equal(t, int64(5), cfg.XP.Correct, "should be equal")
+		assert.Equal(t, []int64{30, 20, 10}, cfg.XP.Placement, "should be equal")
+		assert.Equal(t, int64(100), cfg.XP.LevelBase, "should be equal")
+		assert.Equal(t, 1.5, cfg.XP.LevelExponent, "should be equal")
+	})
+
 	t.Run("TestConfigInvalid", func(t *testing.T) {
 		assert.Panics(t, func() {
 			Init("test_panic", "config")
@@ -0,0 +1,142 @@
+package entity
+
+import "time"
+
+const (
+	BadgeFirstGame    = "FIRST_GAME"
+	BadgeFirstWin     = "FIRST_WIN"
+	BadgePerfectScore = "PERFECT_SCORE"
+	BadgeStreak       = "ANSWER_STREAK"
+	BadgeVeteran      = "VETERAN"
+	BadgeHost         = "SEASONED_HOST"
+
+	// StreakLength is how many correct answers in a row unlock BadgeStreak.
+	StreakLength = 10
+)
+
+// Achievement is a badge a user has unlocked, along with the game that unlocked it.
+type Achievement struct {
+	ID          uint      `json:"id" gorm:"primary_key"`
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_achievements_user_badge"`
+	Badge       string    `json:"badge" gorm:"uniqueIndex:idx_achievements_user_badge"`
+	Name        string    `json:"name" gorm:"-"`
+	Description string    `json:"description" gorm:"-"`
+	InstanceID  uint      `json:"instance_id"`
+	UnlockedAt  time.Time `json:"unlocked_at" gorm:"default:current_timestamp"`
+}
+
+// Progress is what achievement rules are evaluated against once a game has finished. The counts
+// include that game, while Streak and Perfect describe the player's latest answers.
+type Progress struct {
+	Games   int64
+	Wins    int64
+	Hosted  int64
+	Streak  int
+	Perfect bool
+}
+
+type AchievementRule struct {
+	Badge       string
+	Name        string
+	Description string
+	Unlocked    func(p Progress) bool
+}
+
+var AchievementRules = []AchievementRule{
+	{
+		Badge:       BadgeFirstGame,
+		Name:        "First steps",
+		Description: "Finish your first game.",
+		Unlocked:    func(p Progress) bool { return p.Games >= 1 },
+	},
+	{
+		Badge:       BadgeFirstWin,
+		Name:        "Winner",
+		Description: "Top the leaderboard of a game.",
+		Unlocked:    func(p Progress) bool { return p.Wins >= 1 },
+	},
+	{
+		Badge:       BadgePerfectScore,
+		Name:        "Flawless",
+		Description: "Answer every question of a game correctly.",
+		Unlocked:    func(p Progress) bool { return p.Perfect },
+	},
+	{
+		Badge:       BadgeStreak,
+		Name:        "On a roll",
+		Description: "Answer 10 questions in a row correctly.",
+		Unlocked:    func(p Progress) bool { return p.Streak >= StreakLength },
+	},
+	{
+		Badge:       BadgeVeteran,
+		Name:        "Veteran",
+		Description: "Finish 50 games.",
+		Unlocked:    func(p Progress) bool { return p.Games >= 50 },
+	},
+	{
+		Badge:       BadgeHost,
+		Name:        "Seasoned host",
+		Description: "Host 10 games.",
+		Unlocked:    func(p Progress) bool { return p.Hosted >= 10 },
+	},
+}
+
+// NewAchievements returns the achievements progress unlocks that the user does not have yet.
+func NewAchievements(userID, instID uint, progress Progress, unlocked []Achievement) []*Achievement {
+	has := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		has[achievement.Badge] = true
+	}
+
+	var achievements []*Achievement
+	for _, rule := range AchievementRules {
+		if has[rule.Badge] || !rule.Unlocked(progress) {
+			continue
+		}
+
+		achievements = append(achievements, &Achievement{
+			UserID:      userID,
+			Badge:       rule.Badge,
+			Name:        rule.Name,
+			Description: rule.Description,
+			InstanceID:  instID,
+			UnlockedAt:  time.Now(),
+		})
+	}
+
+	return achievements
+}
+
+// DescribeAchievements fills in the name and description of stored achievements.
+func DescribeAchievements(achievements []Achievement) []Achievement {
+	for i := range achievements {
+		for _, rule := range AchievementRules {
+			if rule.Badge == achievements[i].Badge {
+				achievements[i].Name = rule.Name
+				achievements[i].Description = rule.Description
+			}
+		}
+	}
+
+	return achievements
+}
+
+// Streak counts the correct answers in a row at the start of answers, which must be newest first.
+func Streak(answers []Answer) int {
+	for i, answer := range answers {
+		if !answer.Correct {
+			return i
+		}
+	}
+
+	return len(answers)
+}
+
+// PerfectGame reports whether answers, from one game of questions questions, are all correct.
+func PerfectGame(answers []Answer, questions int) bool {
+	if questions == 0 || len(answers) != questions {
+		return false
+	}
+
+	return Streak(answers) == len(answers)
+}
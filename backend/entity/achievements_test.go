@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAchievements(t *testing.T) {
+	t.Run("TestUnlock", func(t *testing.T) {
+		progress := Progress{Games: 1, Wins: 1, Perfect: true}
+
+		actual := NewAchievements(2, 7, progress, nil)
+
+		assert.Len(t, actual, 3)
+		assert.Equal(t, BadgeFirstGame, actual[0].Badge)
+		assert.Equal(t, BadgeFirstWin, actual[1].Badge)
+		assert.Equal(t, BadgePerfectScore, actual[2].Badge)
+		assert.Equal(t, uint(7), actual[0].InstanceID)
+		assert.Equal(t, "Winner", actual[1].Name)
+	})
+
+	t.Run("TestAlreadyUnlocked", func(t *testing.T) {
+		progress := Progress{Games: 50, Hosted: 10, Streak: StreakLength}
+		unlocked := []Achievement{{Badge: BadgeFirstGame}, {Badge: BadgeStreak}}
+
+		actual := NewAchievements(2, 7, progress, unlocked)
+
+		assert.Len(t, actual, 2)
+		assert.Equal(t, BadgeVeteran, actual[0].Badge)
+		assert.Equal(t, BadgeHost, actual[1].Badge)
+	})
+}
+
+func TestStreak(t *testing.T) {
+	answers := []Answer{{Correct: true}, {Correct: true}, {Correct: false}, {Correct: true}}
+
+	assert.Equal(t, 2, Streak(answers))
+	assert.Equal(t, 2, Streak(answers[:2]))
+	assert.Equal(t, 0, Streak(nil))
+}
+
+func TestPerfectGame(t *testing.T) {
+	assert.True(t, PerfectGame([]Answer{{Correct: true}, {Correct: true}}, 2))
+	assert.False(t, PerfectGame([]Answer{{Correct: true}}, 2))
+	assert.False(t, PerfectGame([]Answer{{Correct: true}, {Correct: false}}, 2))
+	assert.False(t, PerfectGame(nil, 0))
+}
@@ -0,0 +1,111 @@
+package entity
+
+const (
+	TooEasy = "too_easy"
+	TooHard = "too_hard"
+
+	// Questions answered fewer times than this are not flagged, the sample is too small to tell.
+	minFlagAnswers = 5
+	tooEasyRate    = 0.9
+	tooHardRate    = 0.2
+)
+
+type GameAnalytics struct {
+	GameID       uint                 `json:"game_id"`
+	Plays        int64                `json:"plays"`
+	AverageScore float64              `json:"average_score"`
+	Questions    []*QuestionAnalytics `json:"questions"`
+}
+
+type QuestionAnalytics struct {
+	QuestionID          uint    `json:"question_id"`
+	Name                string  `json:"name"`
+	Answers             int64   `json:"answers"`
+	CorrectRate         float64 `json:"correct_rate"`
+	AverageResponseTime float64 `json:"average_response_time"`
+	CommonWrongOption   *Option `json:"common_wrong_option"`
+	Difficulty          string  `json:"difficulty"`
+}
+
+type SessionStats struct {
+	Plays        int64
+	AverageScore float64
+}
+
+type QuestionStats struct {
+	QuestionID          uint
+	Answers             int64
+	Correct             int64
+	AverageResponseTime float64
+}
+
+type OptionStats struct {
+	QuestionID uint
+	OptionID   uint
+	Picks      int64
+}
+
+// NewGameAnalytics combines aggregated session and answer statistics for the questions game currently has.
+// wrong only needs to contain picks of incorrect options.
+func NewGameAnalytics(game *Game, sessions SessionStats, questions []QuestionStats, wrong []OptionStats) *GameAnalytics {
+	analytics := &GameAnalytics{
+		GameID:       game.ID,
+		Plays:        sessions.Plays,
+		AverageScore: sessions.AverageScore,
+		Questions:    []*QuestionAnalytics{},
+	}
+
+	stats := make(map[uint]QuestionStats)
+	for _, q := range questions {
+		stats[q.QuestionID] = q
+	}
+
+	mostPicked := make(map[uint]OptionStats)
+	for _, o := range wrong {
+		if o.Picks > mostPicked[o.QuestionID].Picks {
+			mostPicked[o.QuestionID] = o
+		}
+	}
+
+	for _, question := range game.Questions {
+		q := &QuestionAnalytics{
+			QuestionID: question.ID,
+			Name:       question.Name,
+		}
+
+		if s, ok := stats[question.ID]; ok && s.Answers > 0 {
+			q.Answers = s.Answers
+			q.CorrectRate = float64(s.Correct) / float64(s.Answers)
+			q.AverageResponseTime = s.AverageResponseTime
+		}
+
+		if picked, ok := mostPicked[question.ID]; ok {
+			for _, option := range question.Options {
+				if option.ID == picked.OptionID {
+					q.CommonWrongOption = option
+				}
+			}
+		}
+
+		q.Difficulty = difficulty(q)
+		analytics.Questions = append(analytics.Questions, q)
+	}
+
+	return analytics
+}
+
+func difficulty(q *QuestionAnalytics) string {
+	if q.Answers < minFlagAnswers {
+		return ""
+	}
+
+	if q.CorrectRate >= tooEasyRate {
+		return TooEasy
+	}
+
+	if q.CorrectRate <= tooHardRate {
+		return TooHard
+	}
+
+	return ""
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGameAnalytics(t *testing.T) {
+	game := &Game{
+		ID: 1,
+		Questions: []*Question{
+			{ID: 10, Name: "Easy", Options: []*Option{{ID: 100, Correct: true}, {ID: 101}}},
+			{ID: 11, Name: "Hard", Options: []*Option{{ID: 110, Correct: true}, {ID: 111}, {ID: 112}, {ID: 113}}},
+			{ID: 12, Name: "Unplayed", Options: []*Option{{ID: 120, Correct: true}, {ID: 121}}},
+		},
+	}
+
+	sessions := SessionStats{Plays: 2, AverageScore: 4.5}
+	questions := []QuestionStats{
+		{QuestionID: 10, Answers: 10, Correct: 10, AverageResponseTime: 1200},
+		{QuestionID: 11, Answers: 10, Correct: 1, AverageResponseTime: 8000},
+	}
+	wrong := []OptionStats{
+		{QuestionID: 11, OptionID: 111, Picks: 2},
+		{QuestionID: 11, OptionID: 112, Picks: 7},
+	}
+
+	actual := NewGameAnalytics(game, sessions, questions, wrong)
+
+	assert.Equal(t, int64(2), actual.Plays)
+	assert.Equal(t, 4.5, actual.AverageScore)
+	assert.Len(t, actual.Questions, 3)
+
+	t.Run("TestTooEasy", func(t *testing.T) {
+		q := actual.Questions[0]
+		assert.Equal(t, 1.0, q.CorrectRate)
+		assert.Equal(t, 1200.0, q.AverageResponseTime)
+		assert.Nil(t, q.CommonWrongOption)
+		assert.Equal(t, TooEasy, q.Difficulty)
+	})
+
+	t.Run("TestTooHard", func(t *testing.T) {
+		q := actual.Questions[1]
+		assert.Equal(t, 0.1, q.CorrectRate)
+		assert.Equal(t, uint(112), q.CommonWrongOption.ID)
+		assert.Equal(t, TooHard, q.Difficulty)
+	})
+
+	t.Run("TestUnplayed", func(t *testing.T) {
+		q := actual.Questions[2]
+		assert.Equal(t, int64(0), q.Answers)
+		assert.Equal(t, "", q.Difficulty)
+	})
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+)
+
+type Answer struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	InstanceID   uint      `json:"instance_id" gorm:"index"`
+	GameID       uint      `json:"game_id" gorm:"index"`
+	QuestionID   uint      `json:"question_id"`
+	UserID       uint      `json:"user_id" gorm:"index"`
+	OptionID     uint      `json:"option_id"`
+	Correct      bool      `json:"correct"`
+	ResponseTime int64     `json:"response_time"`
+	Points       float64   `json:"points"`
+	CreatedAt    time.Time `json:"created_at" gorm:"default:current_timestamp"`
+}
+
+// NewAnswer records what userID chose for question. A nil option means the round ended without an answer.
+// Response time is stored in milliseconds and points are only awarded for a correct option.
+func NewAnswer(instID, gameID, userID uint, question *Question, option *Option, responseTime time.Duration, points float64) *Answer {
+	answer := &Answer{
+		InstanceID: instID,
+		GameID:     gameID,
+		QuestionID: question.ID,
+		UserID:     userID,
+	}
+
+	if option == nil {
+		return answer
+	}
+
+	answer.OptionID = option.ID
+	answer.Correct = option.Correct
+	answer.ResponseTime = responseTime.Milliseconds()
+	if answer.Correct {
+		answer.Points = points
+	}
+
+	return answer
+}
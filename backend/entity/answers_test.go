@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAnswer(t *testing.T) {
+	question := &Question{
+		ID: 5,
+		Options: []*Option{
+			{ID: 10, Name: "Red", Correct: true},
+			{ID: 11, Name: "Green", Correct: false},
+		},
+	}
+
+	t.Run("TestCorrect", func(t *testing.T) {
+		actual := NewAnswer(1, 2, 3, question, question.Options[0], 1500*time.Millisecond, 3)
+
+		assert.Equal(t, uint(1), actual.InstanceID)
+		assert.Equal(t, uint(5), actual.QuestionID)
+		assert.Equal(t, uint(10), actual.OptionID)
+		assert.True(t, actual.Correct)
+		assert.Equal(t, int64(1500), actual.ResponseTime)
+		assert.Equal(t, float64(3), actual.Points)
+	})
+
+	t.Run("TestWrong", func(t *testing.T) {
+		actual := NewAnswer(1, 2, 3, question, question.Options[1], time.Second, 3)
+
+		assert.False(t, actual.Correct)
+		assert.Equal(t, float64(0), actual.Points)
+	})
+
+	t.Run("TestUnanswered", func(t *testing.T) {
+		actual := NewAnswer(1, 2, 3, question, nil, 0, 3)
+
+		assert.Equal(t, uint(0), actual.OptionID)
+		assert.False(t, actual.Correct)
+		assert.Equal(t, int64(0), actual.ResponseTime)
+	})
+}
@@ -0,0 +1,213 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+
+	maxTags = 10
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N} -]{1,32}$`)
+
+// Tags is a list of tags, stored as a JSON array in a text column.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+
+	bytes, err := json.Marshal(t)
+	return string(bytes), err
+}
+
+func (t *Tags) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*t = Tags{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case []byte:
+		return json.Unmarshal(v, t)
+	default:
+		return fmt.Errorf("cannot scan %T into tags", value)
+	}
+}
+
+func (Tags) GormDataType() string {
+	return "text"
+}
+
+// BankQuestion is a question kept in its owner's question bank, independently of any game. Games use it
+// through a copy that remembers where it came from in Question.BankQuestionID.
+type BankQuestion struct {
+	ID          uint          `json:"id" gorm:"primary_key"`
+	OwnerID     uint          `json:"owner_id"`
+	Name        string        `json:"name"`
+	Explanation string        `json:"explanation"`
+	Difficulty  string        `json:"difficulty"`
+	Tags        Tags          `json:"tags"`
+	Options     []*BankOption `json:"options"`
+	UsedBy      []uint        `json:"used_by" gorm:"-"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"default:current_timestamp"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type BankOption struct {
+	ID             uint   `json:"id" gorm:"primary_key"`
+	Name           string `json:"name"`
+	Correct        bool   `json:"correct"`
+	BankQuestionID uint   `json:"-"`
+}
+
+type CreateBankQuestion struct {
+	Name        string         `json:"name"`
+	Explanation string         `json:"explanation"`
+	Difficulty  string         `json:"difficulty"`
+	Tags        []string       `json:"tags"`
+	Options     []CreateOption `json:"options"`
+}
+
+// BankFilter narrows down a search of a question bank. Cursor is the ID of the last question of the previous page.
+type BankFilter struct {
+	Query      string
+	Tag        string
+	Difficulty string
+	Cursor     uint
+	Limit      int
+}
+
+type BankPage struct {
+	Questions  []BankQuestion `json:"questions"`
+	NextCursor uint           `json:"next_cursor,omitempty"`
+}
+
+func NewBankQuestion(body CreateBankQuestion, ownerID uint) (*BankQuestion, error) {
+	question := &BankQuestion{OwnerID: ownerID}
+	if err := question.Apply(body); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// Apply replaces the contents of b with body and validates the result.
+func (b *BankQuestion) Apply(body CreateBankQuestion) error {
+	tags, err := NormalizeTags(body.Tags)
+	if err != nil {
+		return err
+	}
+
+	b.Name = body.Name
+	b.Explanation = body.Explanation
+	b.Difficulty = body.Difficulty
+	b.Tags = tags
+	b.Options = nil
+	for _, o := range body.Options {
+		b.Options = append(b.Options, &BankOption{Name: o.Name, Correct: o.Correct})
+	}
+
+	if b.Difficulty == "" {
+		b.Difficulty = DifficultyMedium
+	}
+
+	return b.Validate()
+}
+
+func (b *BankQuestion) Validate() error {
+	if len(b.Name) < 1 || len(b.Name) > 256 {
+		return errors.New("name must be between 1 and 256 characters long")
+	}
+
+	if b.Difficulty != DifficultyEasy && b.Difficulty != DifficultyMedium && b.Difficulty != DifficultyHard {
+		return errors.New("difficulty must be easy, medium or hard")
+	}
+
+	if len(b.Options) != 2 && len(b.Options) != 4 {
+		return errors.New("should be 2 or 4 options")
+	}
+
+	return nil
+}
+
+// CreateQuestion returns what a game needs to add a copy of b.
+func (b *BankQuestion) CreateQuestion() CreateQuestion {
+	question := CreateQuestion{
+		Name:           b.Name,
+		Explanation:    b.Explanation,
+		BankQuestionID: b.ID,
+	}
+
+	for _, option := range b.Options {
+		question.Options = append(question.Options, CreateOption{Name: option.Name, Correct: option.Correct})
+	}
+
+	return question
+}
+
+// ApplyBankQuestion copies b into the game's questions made from it. Questions with as many options are
+// edited in place, the rest are replaced by a new copy. It returns the IDs of the replaced questions, which
+// the caller deletes.
+func (g *Game) ApplyBankQuestion(b *BankQuestion) []uint {
+	var removed []uint
+	for i, q := range g.Questions {
+		if q.BankQuestionID != b.ID {
+			continue
+		}
+
+		if len(q.Options) != len(b.Options) {
+			copied, err := NewQuestion(b.CreateQuestion())
+			if err != nil {
+				continue
+			}
+			removed = append(removed, q.ID)
+			g.Questions[i] = copied
+			continue
+		}
+
+		q.Name = b.Name
+		q.Explanation = b.Explanation
+		for j, option := range b.Options {
+			q.Options[j].Name = option.Name
+			q.Options[j].Correct = option.Correct
+		}
+	}
+	return removed
+}
+
+// NormalizeTags lowercases and trims tags and drops duplicates. Tags may hold letters, digits, spaces and
+// dashes, up to 32 of them.
+func NormalizeTags(tags []string) (Tags, error) {
+	normalized := Tags{}
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("should be at most %d tags", maxTags)
+	}
+
+	return normalized, nil
+}
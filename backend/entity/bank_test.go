@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBankQuestion(t *testing.T) {
+	body := CreateBankQuestion{
+		Name:        "What color is tomato?",
+		Explanation: "Ripe ones are red.",
+		Tags:        []string{" Food", "colors", "food "},
+		Options:     []CreateOption{{Name: "Red", Correct: true}, {Name: "Green"}},
+	}
+
+	t.Run("TestValid", func(t *testing.T) {
+		actual, err := NewBankQuestion(body, 3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(3), actual.OwnerID)
+		assert.Equal(t, DifficultyMedium, actual.Difficulty)
+		assert.Equal(t, Tags{"food", "colors"}, actual.Tags)
+		assert.Len(t, actual.Options, 2)
+	})
+
+	t.Run("TestInvalidDifficulty", func(t *testing.T) {
+		invalid := body
+		invalid.Difficulty = "impossible"
+
+		_, err := NewBankQuestion(invalid, 3)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestInvalidTag", func(t *testing.T) {
+		invalid := body
+		invalid.Tags = []string{"100%"}
+
+		_, err := NewBankQuestion(invalid, 3)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestInvalidOptions", func(t *testing.T) {
+		invalid := body
+		invalid.Options = []CreateOption{{Name: "Red", Correct: true}}
+
+		_, err := NewBankQuestion(invalid, 3)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestBankQuestionCreateQuestion(t *testing.T) {
+	bank := &BankQuestion{
+		ID:          5,
+		Name:        "Tomato?",
+		Explanation: "Red.",
+		Options:     []*BankOption{{Name: "Red", Correct: true}, {Name: "Green"}},
+	}
+
+	actual, err := NewQuestion(bank.CreateQuestion())
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint(5), actual.BankQuestionID)
+	assert.Equal(t, "Red.", actual.Explanation)
+	assert.True(t, actual.Options[0].Correct)
+}
+
+func TestApplyBankQuestion(t *testing.T) {
+	bank := &BankQuestion{ID: 5, Name: "Tomato?", Options: []*BankOption{{Name: "Red", Correct: true}, {Name: "Green"}}}
+	game := &Game{Questions: []*Question{
+		{ID: 1, Name: "Old", BankQuestionID: 5, Options: []*Option{{ID: 2, Name: "Red"}, {ID: 3, Name: "Green", Correct: true}}},
+		{ID: 4, Name: "Other", Options: []*Option{{Name: "Yes", Correct: true}, {Name: "No"}}},
+		{ID: 6, Name: "Old", BankQuestionID: 5, Options: []*Option{{Name: "Red", Correct: true}, {Name: "Green"}, {Name: "Blue"}}},
+	}}
+
+	removed := game.ApplyBankQuestion(bank)
+
+	assert.Equal(t, []uint{6}, removed)
+	assert.Equal(t, uint(1), game.Questions[0].ID)
+	assert.Equal(t, "Tomato?", game.Questions[0].Name)
+	assert.True(t, game.Questions[0].Options[0].Correct)
+	assert.False(t, game.Questions[0].Options[1].Correct)
+	assert.Equal(t, "Other", game.Questions[1].Name)
+	assert.Zero(t, game.Questions[2].ID)
+	assert.Len(t, game.Questions[2].Options, 2)
+}
+
+func TestTags(t *testing.T) {
+	value, err := Tags{"food", "colors"}.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, `["food","colors"]`, value)
+
+	var tags Tags
+	assert.Nil(t, tags.Scan([]byte(`["food"]`)))
+	assert.Equal(t, Tags{"food"}, tags)
+}
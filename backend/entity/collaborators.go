@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+	RoleHost   = "host"
+)
+
+const (
+	// ActionView covers everything that shows a game's answers: the full game, exports, history and analytics.
+	ActionView = "view"
+	ActionEdit = "edit"
+	ActionHost = "host"
+	// ActionManage covers deleting a game and choosing its collaborators.
+	ActionManage = "manage"
+)
+
+// permissions lists what each role may do with a game.
+var permissions = map[string][]string{
+	RoleOwner:  {ActionView, ActionEdit, ActionHost, ActionManage},
+	RoleEditor: {ActionView, ActionEdit, ActionHost},
+	RoleViewer: {ActionView},
+	RoleHost:   {ActionHost},
+}
+
+// Collaborator gives a user other than the owner a role on a game.
+type Collaborator struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	GameID    uint      `json:"game_id" gorm:"uniqueIndex:idx_game_collaborator"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_game_collaborator"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:current_timestamp"`
+}
+
+type CreateCollaborator struct {
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+func NewCollaborator(body CreateCollaborator, game *Game) (*Collaborator, error) {
+	if body.Role != RoleEditor && body.Role != RoleViewer && body.Role != RoleHost {
+		return nil, errors.New("role should be editor, viewer or host")
+	}
+
+	if body.UserID == 0 || body.UserID == game.Owner {
+		return nil, errors.New("collaborator should be another user")
+	}
+
+	return &Collaborator{GameID: game.ID, UserID: body.UserID, Role: body.Role}, nil
+}
+
+// Role returns userID's role on g, or an empty string for users without one.
+func (g *Game) Role(userID uint) string {
+	if userID == g.Owner {
+		return RoleOwner
+	}
+
+	for _, collaborator := range g.Collaborators {
+		if collaborator.UserID == userID {
+			return collaborator.Role
+		}
+	}
+	return ""
+}
+
+// Can reports whether userID's role on g allows action.
+func (g *Game) Can(userID uint, action string) bool {
+	for _, allowed := range permissions[g.Role(userID)] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
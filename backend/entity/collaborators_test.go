@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCollaborator(t *testing.T) {
+	game := &Game{ID: 1, Owner: 2}
+
+	t.Run("TestValid", func(t *testing.T) {
+		actual, err := NewCollaborator(CreateCollaborator{UserID: 3, Role: RoleEditor}, game)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), actual.GameID)
+		assert.Equal(t, uint(3), actual.UserID)
+		assert.Equal(t, RoleEditor, actual.Role)
+	})
+
+	t.Run("TestInvalidRole", func(t *testing.T) {
+		_, err := NewCollaborator(CreateCollaborator{UserID: 3, Role: RoleOwner}, game)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TestOwner", func(t *testing.T) {
+		_, err := NewCollaborator(CreateCollaborator{UserID: 2, Role: RoleViewer}, game)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGamePermissions(t *testing.T) {
+	game := &Game{
+		Owner: 1,
+		Collaborators: []*Collaborator{
+			{UserID: 2, Role: RoleEditor},
+			{UserID: 3, Role: RoleViewer},
+			{UserID: 4, Role: RoleHost},
+		},
+	}
+
+	tests := []struct {
+		user    uint
+		role    string
+		allowed []string
+	}{
+		{1, RoleOwner, []string{ActionView, ActionEdit, ActionHost, ActionManage}},
+		{2, RoleEditor, []string{ActionView, ActionEdit, ActionHost}},
+		{3, RoleViewer, []string{ActionView}},
+		{4, RoleHost, []string{ActionHost}},
+		{5, "", nil},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.role, game.Role(test.user))
+
+		for _, action := range []string{ActionView, ActionEdit, ActionHost, ActionManage} {
+			assert.Equal(t, contains(test.allowed, action), game.Can(test.user, action), "user %d, %s", test.user, action)
+		}
+	}
+}
+
+func contains(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
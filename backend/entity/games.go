@@ -2,21 +2,38 @@ package entity
 
 import (
 	"errors"
+	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/ip-05/quizzus/utils"
 )
 
+const (
+	GameDraft     = "draft"
+	GamePublished = "published"
+)
+
+// Game is a quiz. Drafts can be saved before they have any questions, but cannot be hosted or listed
+// until they are published.
 type Game struct {
-	ID         uint        `json:"id" gorm:"primary_key"`
-	InviteCode string      `json:"invite_code"`
-	Topic      string      `json:"topic"`
-	RoundTime  int         `json:"round_time"`
-	Points     float64     `json:"points"`
-	Public     bool        `json:"public"`
-	Questions  []*Question `json:"questions"`
-	Owner      uint        `json:"owner_id"`
-	CreatedAt  time.Time   `json:"created_at" gorm:"default:current_timestamp"`
+	ID               uint            `json:"id" gorm:"primary_key"`
+	InviteCode       string          `json:"invite_code"`
+	Topic            string          `json:"topic"`
+	RoundTime        int             `json:"round_time"`
+	Points           float64         `json:"points"`
+	Public           bool            `json:"public"`
+	ShuffleQuestions bool            `json:"shuffle_questions"`
+	ShuffleOptions   bool            `json:"shuffle_options"`
+	PoolSize         int             `json:"pool_size"`
+	Questions        []*Question     `json:"questions"`
+	Collaborators    []*Collaborator `json:"collaborators"`
+	Owner            uint            `json:"owner_id"`
+	ForkedFrom       uint            `json:"forked_from,omitempty"`
+	ForkedFromOwner  uint            `json:"forked_from_owner,omitempty"`
+	Revision         int             `json:"revision"`
+	Status           string          `json:"status" gorm:"default:published"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"default:current_timestamp"`
 }
 
 type FavoriteGame struct {
@@ -25,20 +42,30 @@ type FavoriteGame struct {
 	UserID uint `json:"user_id"`
 }
 
+// CreateGame describes a new game. With a PoolSize, each run draws that many of the questions at random.
 type CreateGame struct {
-	Topic     string           `json:"topic"`
-	RoundTime int              `json:"round_time"`
-	Points    float64          `json:"points"`
-	Public    bool             `json:"public"`
-	Questions []CreateQuestion `json:"questions"`
+	Topic            string           `json:"topic"`
+	RoundTime        int              `json:"round_time"`
+	Points           float64          `json:"points"`
+	Public           bool             `json:"public"`
+	ShuffleQuestions bool             `json:"shuffle_questions"`
+	ShuffleOptions   bool             `json:"shuffle_options"`
+	PoolSize         int              `json:"pool_size"`
+	Draft            bool             `json:"draft"`
+	Questions        []CreateQuestion `json:"questions"`
 }
 
+// UpdateGame replaces a game's content. The shuffle and pool settings are left as they are when missing.
 type UpdateGame struct {
-	Topic     string           `json:"topic"`
-	RoundTime int              `json:"round_time"`
-	Points    float64          `json:"points"`
-	Public    bool             `json:"public"`
-	Questions []UpdateQuestion `json:"questions"`
+	Topic            string           `json:"topic"`
+	RoundTime        int              `json:"round_time"`
+	Points           float64          `json:"points"`
+	Public           bool             `json:"public"`
+	ShuffleQuestions *bool            `json:"shuffle_questions"`
+	ShuffleOptions   *bool            `json:"shuffle_options"`
+	PoolSize         *int             `json:"pool_size"`
+	Revision         int              `json:"revision"`
+	Questions        []UpdateQuestion `json:"questions"`
 }
 
 func NewGame(body CreateGame, ownerID uint) (*Game, error) {
@@ -50,6 +77,16 @@ func NewGame(body CreateGame, ownerID uint) (*Game, error) {
 		Points:     body.Points,
 		Public:     body.Public,
 		Owner:      ownerID,
+		Revision:   1,
+		Status:     GamePublished,
+
+		ShuffleQuestions: body.ShuffleQuestions,
+		ShuffleOptions:   body.ShuffleOptions,
+		PoolSize:         body.PoolSize,
+	}
+
+	if body.Draft {
+		game.Status = GameDraft
 	}
 
 	for _, q := range body.Questions {
@@ -81,8 +118,100 @@ func (g *Game) Validate() error {
 		return errors.New("points should not be lower than 0")
 	}
 
-	if len(g.Questions) < 1 {
+	if len(g.Questions) < 1 && g.Published() {
 		return errors.New("should be at least 1 question")
 	}
+
+	if g.PoolSize < 0 || g.PoolSize > len(g.Questions) {
+		return errors.New("pool size should not be lower than 0 or over the number of questions")
+	}
 	return nil
 }
+
+// Published reports whether g can be hosted and listed. Games from before drafts count as published.
+func (g *Game) Published() bool {
+	return g.Status != GameDraft
+}
+
+// Publish makes a draft hostable once it passes full validation.
+func (g *Game) Publish() error {
+	status := g.Status
+	g.Status = GamePublished
+
+	if err := g.Validate(); err != nil {
+		g.Status = status
+		return err
+	}
+	return nil
+}
+
+// Clone copies g into a new private game owned by ownerID, crediting g and its owner. Bank links are only
+// kept when the bank is ownerID's own.
+func (g *Game) Clone(ownerID uint) (*Game, error) {
+	body := CreateGame{
+		Topic:            g.Topic,
+		RoundTime:        g.RoundTime,
+		Points:           g.Points,
+		ShuffleQuestions: g.ShuffleQuestions,
+		ShuffleOptions:   g.ShuffleOptions,
+		PoolSize:         g.PoolSize,
+		Draft:            !g.Published(),
+	}
+
+	for _, q := range g.Questions {
+		question := CreateQuestion{Name: q.Name, Explanation: q.Explanation}
+		if g.Owner == ownerID {
+			question.BankQuestionID = q.BankQuestionID
+		}
+		for _, o := range q.Options {
+			question.Options = append(question.Options, CreateOption{Name: o.Name, Correct: o.Correct})
+		}
+		body.Questions = append(body.Questions, question)
+	}
+
+	clone, err := NewGame(body, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	clone.ForkedFrom = g.ID
+	clone.ForkedFromOwner = g.Owner
+	return clone, nil
+}
+
+// QuestionCount returns how many questions a run of g asks.
+func (g *Game) QuestionCount() int {
+	if g.PoolSize > 0 {
+		return g.PoolSize
+	}
+	return len(g.Questions)
+}
+
+// Draw picks the questions for one run of g: QuestionCount of them, in random order with ShuffleQuestions
+// and in their usual order otherwise.
+func (g *Game) Draw(rng *rand.Rand) []*Question {
+	picked := rng.Perm(len(g.Questions))[:g.QuestionCount()]
+	if !g.ShuffleQuestions {
+		sort.Ints(picked)
+	}
+
+	questions := make([]*Question, len(picked))
+	for i, index := range picked {
+		questions[i] = g.Questions[index]
+	}
+	return questions
+}
+
+// OptionOrder returns the order to show n options in to one player, as indexes into the question's options.
+// Without shuffle it is their usual order.
+func OptionOrder(n int, shuffle bool, rng *rand.Rand) []int {
+	if shuffle {
+		return rng.Perm(n)
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"math/rand"
 	"strings"
 	"testing"
 
@@ -110,9 +111,118 @@ func TestValidateGame(t *testing.T) {
 		assert.Contains(t, errValidate.Error(), "points should not be lower than 0")
 		actual.Points = 10
 	})
+	t.Run("TestPoolSize", func(t *testing.T) {
+		actual.PoolSize = 2
+		errValidate := actual.Validate()
+		assert.Contains(t, errValidate.Error(), "pool size should not be lower than 0 or over the number of questions")
+		actual.PoolSize = 0
+	})
 	t.Run("TestQuestions", func(t *testing.T) {
 		actual.Questions = []*Question{}
 		errValidate := actual.Validate()
 		assert.Contains(t, errValidate.Error(), "should be at least 1 question")
 	})
 }
+
+func TestDraftGame(t *testing.T) {
+	draft, err := NewGame(CreateGame{Topic: "Half-written", RoundTime: 10, Points: 3, Draft: true}, uint(123))
+
+	assert.Nil(t, err)
+	assert.Equal(t, GameDraft, draft.Status)
+	assert.False(t, draft.Published())
+
+	t.Run("TestPublishIncomplete", func(t *testing.T) {
+		err := draft.Publish()
+
+		assert.Contains(t, err.Error(), "should be at least 1 question")
+		assert.Equal(t, GameDraft, draft.Status)
+	})
+
+	t.Run("TestPublish", func(t *testing.T) {
+		draft.Questions = []*Question{{Name: "What color is tomato?", Options: []*Option{{Name: "Red", Correct: true}, {Name: "Green"}}}}
+
+		assert.Nil(t, draft.Publish())
+		assert.True(t, draft.Published())
+	})
+
+	t.Run("TestLegacyGame", func(t *testing.T) {
+		assert.True(t, (&Game{}).Published())
+	})
+}
+
+func TestCloneGame(t *testing.T) {
+	game := &Game{
+		ID:         7,
+		InviteCode: "ABCDEF",
+		Topic:      "My game",
+		RoundTime:  10,
+		Points:     3,
+		Public:     true,
+		Owner:      1,
+		Questions: []*Question{
+			{ID: 4, Name: "What color is tomato?", BankQuestionID: 9, Options: []*Option{{ID: 5, Name: "Red", Correct: true}, {ID: 6, Name: "Green"}}},
+		},
+	}
+
+	t.Run("TestOtherOwner", func(t *testing.T) {
+		actual, err := game.Clone(2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(2), actual.Owner)
+		assert.Equal(t, uint(7), actual.ForkedFrom)
+		assert.Equal(t, uint(1), actual.ForkedFromOwner)
+		assert.False(t, actual.Public)
+		assert.NotEqual(t, game.InviteCode, actual.InviteCode)
+		assert.Zero(t, actual.Questions[0].ID)
+		assert.Zero(t, actual.Questions[0].BankQuestionID)
+		assert.True(t, actual.Questions[0].Options[0].Correct)
+		assert.NotSame(t, game.Questions[0], actual.Questions[0])
+	})
+
+	t.Run("TestSameOwner", func(t *testing.T) {
+		actual, err := game.Clone(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(9), actual.Questions[0].BankQuestionID)
+	})
+}
+
+func TestDrawQuestions(t *testing.T) {
+	game := &Game{Questions: []*Question{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}}
+	ids := func(questions []*Question) []uint {
+		var ids []uint
+		for _, q := range questions {
+			ids = append(ids, q.ID)
+		}
+		return ids
+	}
+
+	t.Run("TestAll", func(t *testing.T) {
+		assert.Equal(t, []uint{1, 2, 3, 4, 5}, ids(game.Draw(rand.New(rand.NewSource(1)))))
+	})
+
+	t.Run("TestPool", func(t *testing.T) {
+		game.PoolSize = 3
+		actual := ids(game.Draw(rand.New(rand.NewSource(1))))
+
+		assert.Len(t, actual, 3)
+		assert.IsIncreasing(t, actual)
+		assert.Equal(t, 3, game.QuestionCount())
+	})
+
+	t.Run("TestShuffle", func(t *testing.T) {
+		game.PoolSize = 0
+		game.ShuffleQuestions = true
+		actual := ids(game.Draw(rand.New(rand.NewSource(1))))
+
+		assert.ElementsMatch(t, []uint{1, 2, 3, 4, 5}, actual)
+		assert.NotEqual(t, []uint{1, 2, 3, 4, 5}, actual)
+	})
+}
+
+func TestOptionOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	assert.Equal(t, []int{0, 1, 2, 3}, OptionOrder(4, false, rng))
+	assert.ElementsMatch(t, []int{0, 1, 2, 3}, OptionOrder(4, true, rng))
+}
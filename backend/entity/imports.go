@@ -0,0 +1,29 @@
+package entity
+
+import "fmt"
+
+// ImportError is a problem with the imported question that starts at Line, counting from 1. Line 0 is
+// a problem with the game as a whole. Documents made of several files, such as QTI packages, name the
+// File and count questions rather than lines.
+type ImportError struct {
+	Line    int    `json:"line"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ImportError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ImportResult is the game made from an imported document. Errors are questions that could not be read,
+// Warnings are questions of a type games do not have, which are skipped. Dry runs return the game without
+// saving it.
+type ImportResult struct {
+	Game     *Game         `json:"game"`
+	Errors   []ImportError `json:"errors"`
+	Warnings []ImportError `json:"warnings"`
+	DryRun   bool          `json:"dry_run"`
+}
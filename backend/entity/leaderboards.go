@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	WindowAll    = "all"
+	WindowWeek   = "week"
+	WindowMonth  = "month"
+	WindowSeason = "season"
+
+	SortPoints  = "points"
+	SortBest    = "best"
+	SortWinRate = "win_rate"
+
+	// MinRankedGames is how many games a player needs before being ranked by win rate.
+	MinRankedGames = 5
+)
+
+// PlayerStanding is a player's aggregated result over all public quizzes, or over one quiz.
+// Best is the player's high score in a single game and Wins counts games they topped.
+type PlayerStanding struct {
+	Rank    int     `json:"rank"`
+	UserID  uint    `json:"user_id"`
+	Name    string  `json:"name"`
+	Picture string  `json:"picture"`
+	Points  float64 `json:"points"`
+	Best    float64 `json:"best"`
+	Games   int64   `json:"games"`
+	Wins    int64   `json:"wins"`
+	WinRate float64 `json:"win_rate"`
+}
+
+// LeaderboardQuery selects a leaderboard. A zero GameID means all public quizzes.
+type LeaderboardQuery struct {
+	GameID uint
+	Window string
+	Sort   string
+	Limit  int
+}
+
+// WindowStart returns when window began as of now. Weeks start on Monday and seasons are calendar
+// quarters, all in UTC. The all-time window starts at the zero time.
+func WindowStart(window string, now time.Time) (time.Time, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch window {
+	case WindowAll, "":
+		return time.Time{}, nil
+	case WindowWeek:
+		weekday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -weekday), nil
+	case WindowMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case WindowSeason:
+		quarter := (int(now.Month()) - 1) / 3
+		return time.Date(now.Year(), time.Month(quarter*3+1), 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, errors.New("window must be all, week, month or season")
+	}
+}
+
+// RankStandings fills in win rates and ranks standings, which must already be ordered by sort.
+// Players level on the sorted value share a rank.
+func RankStandings(standings []PlayerStanding, sort string) []PlayerStanding {
+	value := func(s PlayerStanding) float64 {
+		switch sort {
+		case SortBest:
+			return s.Best
+		case SortWinRate:
+			return s.WinRate
+		default:
+			return s.Points
+		}
+	}
+
+	for i := range standings {
+		if standings[i].Games > 0 {
+			standings[i].WinRate = float64(standings[i].Wins) / float64(standings[i].Games)
+		}
+
+		standings[i].Rank = i + 1
+		if i > 0 && value(standings[i-1]) == value(standings[i]) {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+
+	return standings
+}
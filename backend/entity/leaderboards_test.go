@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowStart(t *testing.T) {
+	// Wednesday
+	now := time.Date(2023, time.May, 17, 15, 30, 0, 0, time.UTC)
+
+	t.Run("TestAll", func(t *testing.T) {
+		actual, err := WindowStart(WindowAll, now)
+		assert.Nil(t, err)
+		assert.True(t, actual.IsZero())
+	})
+
+	t.Run("TestWeek", func(t *testing.T) {
+		actual, err := WindowStart(WindowWeek, now)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, time.May, 15, 0, 0, 0, 0, time.UTC), actual)
+	})
+
+	t.Run("TestMonth", func(t *testing.T) {
+		actual, err := WindowStart(WindowMonth, now)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC), actual)
+	})
+
+	t.Run("TestSeason", func(t *testing.T) {
+		actual, err := WindowStart(WindowSeason, now)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC), actual)
+	})
+
+	t.Run("TestInvalid", func(t *testing.T) {
+		_, err := WindowStart("year", now)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRankStandings(t *testing.T) {
+	standings := []PlayerStanding{
+		{UserID: 1, Points: 30, Games: 4, Wins: 2},
+		{UserID: 2, Points: 30, Games: 5, Wins: 1},
+		{UserID: 3, Points: 10, Games: 2, Wins: 0},
+	}
+
+	actual := RankStandings(standings, SortPoints)
+
+	assert.Equal(t, 1, actual[0].Rank)
+	assert.Equal(t, 1, actual[1].Rank)
+	assert.Equal(t, 3, actual[2].Rank)
+	assert.Equal(t, 0.5, actual[0].WinRate)
+	assert.Equal(t, 0.2, actual[1].WinRate)
+}
+
+func TestRankStandingsBest(t *testing.T) {
+	standings := []PlayerStanding{
+		{UserID: 1, Best: 20},
+		{UserID: 2, Best: 15},
+		{UserID: 3, Best: 15},
+	}
+
+	actual := RankStandings(standings, SortBest)
+
+	assert.Equal(t, 1, actual[0].Rank)
+	assert.Equal(t, 2, actual[1].Rank)
+	assert.Equal(t, 2, actual[2].Rank)
+	assert.Equal(t, float64(0), actual[0].WinRate)
+}
@@ -0,0 +1,80 @@
+package entity
+
+import "math"
+
+// LevelCurve decides how much XP each level takes: going from level n to n+1 needs Base * n^Exponent.
+type LevelCurve struct {
+	Base     int64
+	Exponent float64
+}
+
+// LevelProgress is where a total amount of XP puts a player on the curve.
+type LevelProgress struct {
+	Level      int     `json:"level"`
+	CurrentXP  int64   `json:"current_xp"`
+	RequiredXP int64   `json:"required_xp"`
+	Progress   float64 `json:"progress"`
+}
+
+// XPRules decide how much XP a finished game is worth: Participation for finishing, Correct per correct
+// answer and Placement[rank-1] for placing in the top ranks.
+type XPRules struct {
+	Participation int64
+	Correct       int64
+	Placement     []int64
+}
+
+// XPGain is the XP a player earned in a game and where it left them.
+type XPGain struct {
+	Gained    int64         `json:"gained"`
+	XP        int64         `json:"xp"`
+	Level     LevelProgress `json:"level"`
+	LeveledUp bool          `json:"leveled_up"`
+}
+
+// Required returns the XP needed to go from level to the next one, never less than one.
+func (c LevelCurve) Required(level int) int64 {
+	required := int64(math.Round(float64(c.Base) * math.Pow(float64(level), c.Exponent)))
+	if required < 1 {
+		return 1
+	}
+	return required
+}
+
+func (c LevelCurve) Progress(xp int64) LevelProgress {
+	level := 1
+	for xp >= c.Required(level) {
+		xp -= c.Required(level)
+		level++
+	}
+
+	required := c.Required(level)
+	return LevelProgress{
+		Level:      level,
+		CurrentXP:  xp,
+		RequiredXP: required,
+		Progress:   math.Round(float64(xp)/float64(required)*100) / 100,
+	}
+}
+
+// Earned returns the XP for finishing a game with correct correct answers at rank, where zero means unranked.
+func (r XPRules) Earned(correct int, rank int) int64 {
+	xp := r.Participation + r.Correct*int64(correct)
+	if rank > 0 && rank <= len(r.Placement) {
+		xp += r.Placement[rank-1]
+	}
+	return xp
+}
+
+// NewXPGain adds gained to a player's xp and reports the level they end up at.
+func NewXPGain(curve LevelCurve, xp int64, gained int64) *XPGain {
+	before := curve.Progress(xp)
+	after := curve.Progress(xp + gained)
+
+	return &XPGain{
+		Gained:    gained,
+		XP:        xp + gained,
+		Level:     after,
+		LeveledUp: after.Level > before.Level,
+	}
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelCurve(t *testing.T) {
+	curve := LevelCurve{Base: 100, Exponent: 2}
+
+	t.Run("TestRequired", func(t *testing.T) {
+		assert.Equal(t, int64(100), curve.Required(1))
+		assert.Equal(t, int64(400), curve.Required(2))
+		assert.Equal(t, int64(1), LevelCurve{}.Required(1))
+	})
+
+	t.Run("TestProgress", func(t *testing.T) {
+		assert.Equal(t, LevelProgress{Level: 1, CurrentXP: 0, RequiredXP: 100, Progress: 0}, curve.Progress(0))
+		assert.Equal(t, LevelProgress{Level: 2, CurrentXP: 0, RequiredXP: 400, Progress: 0}, curve.Progress(100))
+		assert.Equal(t, LevelProgress{Level: 2, CurrentXP: 100, RequiredXP: 400, Progress: 0.25}, curve.Progress(200))
+		assert.Equal(t, 3, curve.Progress(500).Level)
+	})
+}
+
+func TestXPRules(t *testing.T) {
+	rules := XPRules{Participation: 10, Correct: 5, Placement: []int64{30, 20, 10}}
+
+	assert.Equal(t, int64(55), rules.Earned(3, 1))
+	assert.Equal(t, int64(20), rules.Earned(0, 3))
+	assert.Equal(t, int64(15), rules.Earned(1, 4))
+	assert.Equal(t, int64(10), rules.Earned(0, 0))
+}
+
+func TestNewXPGain(t *testing.T) {
+	curve := LevelCurve{Base: 100, Exponent: 2}
+
+	actual := NewXPGain(curve, 90, 20)
+
+	assert.Equal(t, int64(20), actual.Gained)
+	assert.Equal(t, int64(110), actual.XP)
+	assert.Equal(t, 2, actual.Level.Level)
+	assert.True(t, actual.LeveledUp)
+	assert.False(t, NewXPGain(curve, 0, 20).LeveledUp)
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// ProfileStats sums up a user's play history for their public profile.
+type ProfileStats struct {
+	Games           int64           `json:"games"`
+	Hosted          int64           `json:"hosted"`
+	Wins            int64           `json:"wins"`
+	Answers         int64           `json:"answers"`
+	Accuracy        float64         `json:"accuracy"`
+	FavouriteTopics []TopicStats    `json:"favourite_topics"`
+	RecentSessions  []RecentSession `json:"recent_sessions"`
+}
+
+type TopicStats struct {
+	Topic string `json:"topic"`
+	Games int64  `json:"games"`
+}
+
+// RecentSession is a session as shown on a profile, without the game's invite code or the other players.
+type RecentSession struct {
+	ID        uint      `json:"id"`
+	GameID    uint      `json:"game_id"`
+	Topic     string    `json:"topic"`
+	Points    float64   `json:"points"`
+	Rank      int       `json:"rank"`
+	Players   int       `json:"players"`
+	Host      bool      `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// NewProfileStats builds the stats block from the user's progress, answer counts, most played topics and
+// latest sessions, which must already have their leaderboards set.
+func NewProfileStats(progress Progress, answers, correct int64, topics []TopicStats, sessions []GameSession) *ProfileStats {
+	stats := &ProfileStats{
+		Games:           progress.Games,
+		Hosted:          progress.Hosted,
+		Wins:            progress.Wins,
+		Answers:         answers,
+		FavouriteTopics: topics,
+		RecentSessions:  make([]RecentSession, 0, len(sessions)),
+	}
+
+	if stats.FavouriteTopics == nil {
+		stats.FavouriteTopics = []TopicStats{}
+	}
+
+	if answers > 0 {
+		stats.Accuracy = math.Round(float64(correct)/float64(answers)*100) / 100
+	}
+
+	for _, session := range sessions {
+		stats.RecentSessions = append(stats.RecentSessions, RecentSession{
+			ID:        session.ID,
+			GameID:    session.GameID,
+			Topic:     session.Game.Topic,
+			Points:    session.Points,
+			Rank:      session.Rank,
+			Players:   session.Players,
+			Host:      session.Host,
+			StartedAt: session.StartedAt,
+		})
+	}
+
+	return stats
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProfileStats(t *testing.T) {
+	progress := Progress{Games: 4, Wins: 1, Hosted: 2}
+	sessions := []GameSession{
+		{ID: 9, GameID: 3, Points: 12, Rank: 2, Players: 5, Game: Game{Topic: "Geography", InviteCode: "secret"}},
+		{ID: 8, GameID: 1, Host: true, Game: Game{Topic: "History"}},
+	}
+
+	actual := NewProfileStats(progress, 30, 20, nil, sessions)
+
+	assert.Equal(t, int64(4), actual.Games)
+	assert.Equal(t, int64(2), actual.Hosted)
+	assert.Equal(t, int64(1), actual.Wins)
+	assert.Equal(t, 0.67, actual.Accuracy)
+	assert.Equal(t, []TopicStats{}, actual.FavouriteTopics)
+	assert.Len(t, actual.RecentSessions, 2)
+	assert.Equal(t, "Geography", actual.RecentSessions[0].Topic)
+	assert.Equal(t, 2, actual.RecentSessions[0].Rank)
+	assert.True(t, actual.RecentSessions[1].Host)
+
+	t.Run("TestNoAnswers", func(t *testing.T) {
+		actual := NewProfileStats(Progress{}, 0, 0, nil, nil)
+
+		assert.Equal(t, float64(0), actual.Accuracy)
+		assert.Empty(t, actual.RecentSessions)
+	})
+}
@@ -5,26 +5,35 @@ import (
 )
 
 type Question struct {
-	ID      uint      `json:"id" gorm:"primary_key"`
-	Name    string    `json:"name"`
-	Options []*Option `json:"options"`
-	GameID  uint      `json:"-"`
+	ID             uint      `json:"id" gorm:"primary_key"`
+	Name           string    `json:"name"`
+	Explanation    string    `json:"explanation"`
+	Options        []*Option `json:"options"`
+	GameID         uint      `json:"-"`
+	BankQuestionID uint      `json:"bank_question_id,omitempty" gorm:"index"`
 }
 
+// CreateQuestion describes a new question. With a BankQuestionID, the rest is taken from that bank question.
 type CreateQuestion struct {
-	Name    string         `json:"name"`
-	Options []CreateOption `json:"options"`
+	Name           string         `json:"name"`
+	Explanation    string         `json:"explanation"`
+	Options        []CreateOption `json:"options"`
+	BankQuestionID uint           `json:"bank_question_id"`
 }
 
 type UpdateQuestion struct {
-	ID      uint           `json:"id"`
-	Name    string         `json:"name"`
-	Options []UpdateOption `json:"options"`
+	ID             uint           `json:"id"`
+	Name           string         `json:"name"`
+	Explanation    string         `json:"explanation"`
+	Options        []UpdateOption `json:"options"`
+	BankQuestionID uint           `json:"bank_question_id"`
 }
 
 func NewQuestion(q CreateQuestion) (*Question, error) {
 	question := &Question{
-		Name: q.Name,
+		Name:           q.Name,
+		Explanation:    q.Explanation,
+		BankQuestionID: q.BankQuestionID,
 	}
 
 	for _, o := range q.Options {
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DefaultRating = 1200
+
+	// RatingK caps how far a single game can move a rating.
+	RatingK = 32
+
+	// MinRatedGames is how many rated games a player needs to appear on the rating leaderboard.
+	MinRatedGames = 3
+)
+
+// RatingChange is one step of a user's rating history, recorded when a rated game finishes.
+type RatingChange struct {
+	ID         uint      `json:"id" gorm:"primary_key"`
+	UserID     uint      `json:"user_id"`
+	InstanceID uint      `json:"instance_id"`
+	Rank       int       `json:"rank"`
+	Rating     float64   `json:"rating"`
+	Delta      float64   `json:"delta"`
+	CreatedAt  time.Time `json:"created_at" gorm:"default:current_timestamp"`
+}
+
+type RatingStanding struct {
+	Rank       int     `json:"rank"`
+	UserID     uint    `json:"user_id"`
+	Name       string  `json:"name"`
+	Picture    string  `json:"picture"`
+	Rating     float64 `json:"rating"`
+	RatedGames int     `json:"rated_games"`
+}
+
+// NewRatingChanges rates a finished game with multiplayer Elo: every player is scored against every
+// other by final rank, and the sum is scaled so one game moves a rating by at most RatingK. leaderboard
+// must be ranked and ratings holds the current rating of each player in it. Games with fewer than two
+// players are not rated.
+func NewRatingChanges(instID uint, ratings map[uint]float64, leaderboard []Leaderboard) []*RatingChange {
+	if len(leaderboard) < 2 {
+		return nil
+	}
+
+	changes := make([]*RatingChange, 0, len(leaderboard))
+	for _, player := range leaderboard {
+		rating := ratings[player.UserID]
+
+		var score float64
+		for _, opponent := range leaderboard {
+			if opponent.UserID == player.UserID {
+				continue
+			}
+
+			expected := 1 / (1 + math.Pow(10, (ratings[opponent.UserID]-rating)/400))
+
+			actual := 0.5
+			if player.Rank < opponent.Rank {
+				actual = 1
+			} else if player.Rank > opponent.Rank {
+				actual = 0
+			}
+
+			score += actual - expected
+		}
+
+		delta := math.Round(RatingK*score/float64(len(leaderboard)-1)*10) / 10
+		changes = append(changes, &RatingChange{
+			UserID:     player.UserID,
+			InstanceID: instID,
+			Rank:       player.Rank,
+			Rating:     rating + delta,
+			Delta:      delta,
+		})
+	}
+
+	return changes
+}
+
+// RankRatings ranks standings, which must be ordered by rating. Equal ratings share a rank.
+func RankRatings(standings []RatingStanding) []RatingStanding {
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i-1].Rating == standings[i].Rating {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+
+	return standings
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRatingChanges(t *testing.T) {
+	t.Run("TestEqualRatings", func(t *testing.T) {
+		ratings := map[uint]float64{1: DefaultRating, 2: DefaultRating, 3: DefaultRating}
+		leaderboard := []Leaderboard{{Rank: 1, UserID: 1}, {Rank: 2, UserID: 2}, {Rank: 3, UserID: 3}}
+
+		actual := NewRatingChanges(7, ratings, leaderboard)
+
+		assert.Len(t, actual, 3)
+		assert.Equal(t, float64(16), actual[0].Delta)
+		assert.Equal(t, float64(0), actual[1].Delta)
+		assert.Equal(t, float64(-16), actual[2].Delta)
+		assert.Equal(t, float64(1216), actual[0].Rating)
+		assert.Equal(t, uint(7), actual[0].InstanceID)
+	})
+
+	t.Run("TestTie", func(t *testing.T) {
+		ratings := map[uint]float64{1: DefaultRating, 2: DefaultRating}
+		leaderboard := []Leaderboard{{Rank: 1, UserID: 1}, {Rank: 1, UserID: 2}}
+
+		actual := NewRatingChanges(7, ratings, leaderboard)
+
+		assert.Equal(t, float64(0), actual[0].Delta)
+		assert.Equal(t, float64(0), actual[1].Delta)
+	})
+
+	t.Run("TestUpset", func(t *testing.T) {
+		ratings := map[uint]float64{1: 1000, 2: 1400}
+		leaderboard := []Leaderboard{{Rank: 1, UserID: 1}, {Rank: 2, UserID: 2}}
+
+		actual := NewRatingChanges(7, ratings, leaderboard)
+
+		assert.Greater(t, actual[0].Delta, float64(RatingK/2))
+		assert.Equal(t, -actual[0].Delta, actual[1].Delta)
+	})
+
+	t.Run("TestSinglePlayer", func(t *testing.T) {
+		actual := NewRatingChanges(7, map[uint]float64{1: DefaultRating}, []Leaderboard{{Rank: 1, UserID: 1}})
+
+		assert.Nil(t, actual)
+	})
+}
+
+func TestRankRatings(t *testing.T) {
+	actual := RankRatings([]RatingStanding{{Rating: 1300}, {Rating: 1250}, {Rating: 1250}, {Rating: 1100}})
+
+	assert.Equal(t, []int{1, 2, 2, 4}, []int{actual[0].Rank, actual[1].Rank, actual[2].Rank, actual[3].Rank})
+}
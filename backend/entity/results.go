@@ -0,0 +1,155 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InstanceResults is the results sheet of a finished game instance, one entry per player.
+type InstanceResults struct {
+	InstanceID uint            `json:"instance_id"`
+	GameID     uint            `json:"game_id"`
+	Topic      string          `json:"topic"`
+	StartedAt  time.Time       `json:"started_at"`
+	EndedAt    time.Time       `json:"ended_at"`
+	Questions  []*Question     `json:"questions"`
+	Players    []*PlayerResult `json:"players"`
+}
+
+type PlayerResult struct {
+	Rank    int               `json:"rank"`
+	UserID  uint              `json:"user_id"`
+	Name    string            `json:"name"`
+	Points  float64           `json:"points"`
+	Correct int               `json:"correct"`
+	Answers []*QuestionResult `json:"answers"`
+}
+
+type QuestionResult struct {
+	QuestionID   uint    `json:"question_id"`
+	OptionID     uint    `json:"option_id"`
+	Option       string  `json:"option"`
+	Correct      bool    `json:"correct"`
+	Points       float64 `json:"points"`
+	ResponseTime int64   `json:"response_time"`
+}
+
+// AskedQuestions returns the questions that answers were recorded for. Games that draw from a pool only
+// ask some of their questions; without any answers there is no telling which, so all of them are returned.
+func AskedQuestions(questions []*Question, answers []Answer) []*Question {
+	if len(answers) == 0 {
+		return questions
+	}
+
+	asked := make(map[uint]bool)
+	for _, answer := range answers {
+		asked[answer.QuestionID] = true
+	}
+
+	filtered := []*Question{}
+	for _, question := range questions {
+		if asked[question.ID] {
+			filtered = append(filtered, question)
+		}
+	}
+	return filtered
+}
+
+// NewInstanceResults builds the results of instance from its leaderboard, which must be ordered by points,
+// and the answers recorded during it. The host is left out.
+func NewInstanceResults(instance *GameInstance, leaderboard []Leaderboard, answers []Answer) *InstanceResults {
+	results := &InstanceResults{
+		InstanceID: instance.ID,
+		GameID:     instance.GameID,
+		Topic:      instance.Game.Topic,
+		StartedAt:  instance.StartedAt,
+		EndedAt:    instance.EndedAt,
+		Questions:  AskedQuestions(instance.Game.Questions, answers),
+		Players:    []*PlayerResult{},
+	}
+
+	options := make(map[uint]*Option)
+	for _, question := range instance.Game.Questions {
+		for _, option := range question.Options {
+			options[option.ID] = option
+		}
+	}
+
+	byUser := make(map[uint]map[uint]Answer)
+	for _, answer := range answers {
+		if byUser[answer.UserID] == nil {
+			byUser[answer.UserID] = make(map[uint]Answer)
+		}
+		byUser[answer.UserID][answer.QuestionID] = answer
+	}
+
+	for _, entry := range RankLeaderboard(leaderboard, instance.HostID) {
+		player := &PlayerResult{
+			Rank:   entry.Rank,
+			UserID: entry.UserID,
+			Name:   entry.Name,
+			Points: entry.Points,
+		}
+
+		for _, question := range results.Questions {
+			result := &QuestionResult{QuestionID: question.ID}
+
+			if answer, ok := byUser[entry.UserID][question.ID]; ok {
+				result.OptionID = answer.OptionID
+				result.Correct = answer.Correct
+				result.Points = answer.Points
+				result.ResponseTime = answer.ResponseTime
+				if option, ok := options[answer.OptionID]; ok {
+					result.Option = option.Name
+				}
+			}
+
+			if result.Correct {
+				player.Correct++
+			}
+			player.Answers = append(player.Answers, result)
+		}
+
+		results.Players = append(results.Players, player)
+	}
+
+	return results
+}
+
+// Table flattens the results into a header and one row per player, for spreadsheet exports. Names and options
+// are escaped, as players choose them.
+func (r *InstanceResults) Table() ([]string, [][]string) {
+	header := []string{"Rank", "Player", "Points", "Correct"}
+	for i, question := range r.Questions {
+		header = append(header, fmt.Sprintf("Q%d: %s", i+1, question.Name), fmt.Sprintf("Q%d correct", i+1))
+	}
+
+	rows := [][]string{}
+	for _, player := range r.Players {
+		row := []string{
+			strconv.Itoa(player.Rank),
+			escapeCell(player.Name),
+			strconv.FormatFloat(player.Points, 'f', -1, 64),
+			strconv.Itoa(player.Correct),
+		}
+
+		for _, answer := range player.Answers {
+			row = append(row, escapeCell(answer.Option), strconv.FormatBool(answer.Correct))
+		}
+
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+// escapeCell keeps a spreadsheet from running cell as a formula, by prefixing it with a quote when it starts
+// with a character that begins one.
+func escapeCell(cell string) string {
+	if cell != "" && strings.ContainsAny(cell[:1], "=+-@\t\r") {
+		return "'" + cell
+	}
+	return cell
+}
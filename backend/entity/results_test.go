@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInstanceResults(t *testing.T) {
+	instance := &GameInstance{
+		ID:     7,
+		GameID: 1,
+		HostID: 1,
+		Game: Game{
+			ID:    1,
+			Topic: "Colors",
+			Questions: []*Question{
+				{ID: 10, Name: "Tomato?", Options: []*Option{{ID: 100, Name: "Red", Correct: true}, {ID: 101, Name: "Green"}}},
+				{ID: 11, Name: "Grass?", Options: []*Option{{ID: 110, Name: "Red"}, {ID: 111, Name: "Green", Correct: true}}},
+			},
+		},
+	}
+
+	leaderboard := []Leaderboard{
+		{UserID: 2, Name: "Alice", Points: 6},
+		{UserID: 3, Name: "Bob", Points: 3},
+		{UserID: 4, Name: "Carol", Points: 3},
+		{UserID: 1, Name: "Host", Points: 0},
+	}
+
+	answers := []Answer{
+		{UserID: 2, QuestionID: 10, OptionID: 100, Correct: true, Points: 3},
+		{UserID: 2, QuestionID: 11, OptionID: 111, Correct: true, Points: 3},
+		{UserID: 3, QuestionID: 10, OptionID: 100, Correct: true, Points: 3},
+		{UserID: 3, QuestionID: 11, OptionID: 110, Correct: false},
+		{UserID: 4, QuestionID: 11, OptionID: 111, Correct: true, Points: 3},
+	}
+
+	actual := NewInstanceResults(instance, leaderboard, answers)
+
+	t.Run("TestPlayers", func(t *testing.T) {
+		assert.Len(t, actual.Players, 3)
+		assert.Equal(t, 1, actual.Players[0].Rank)
+		assert.Equal(t, 2, actual.Players[0].Correct)
+		assert.Equal(t, 2, actual.Players[1].Rank)
+		assert.Equal(t, 2, actual.Players[2].Rank)
+		assert.Equal(t, "", actual.Players[2].Answers[0].Option)
+		assert.Equal(t, "Red", actual.Players[1].Answers[1].Option)
+	})
+
+	t.Run("TestTable", func(t *testing.T) {
+		header, rows := actual.Table()
+
+		assert.Equal(t, []string{"Rank", "Player", "Points", "Correct", "Q1: Tomato?", "Q1 correct", "Q2: Grass?", "Q2 correct"}, header)
+		assert.Equal(t, []string{"1", "Alice", "6", "2", "Red", "true", "Green", "true"}, rows[0])
+		assert.Len(t, rows, 3)
+	})
+
+	t.Run("TestTableFormulas", func(t *testing.T) {
+		actual.Players[0].Name = "=HYPERLINK(\"http://example.com\")"
+		actual.Players[0].Answers[0].Option = "@SUM(A1)"
+		actual.Players[1].Name = "-1+2"
+		actual.Players[2].Name = "Bob=Alice"
+
+		_, rows := actual.Table()
+
+		assert.Equal(t, "'=HYPERLINK(\"http://example.com\")", rows[0][1])
+		assert.Equal(t, "'@SUM(A1)", rows[0][4])
+		assert.Equal(t, "'-1+2", rows[1][1])
+		assert.Equal(t, "Bob=Alice", rows[2][1])
+	})
+}
+
+func TestAskedQuestions(t *testing.T) {
+	questions := []*Question{{ID: 10}, {ID: 11}, {ID: 12}}
+
+	assert.Len(t, AskedQuestions(questions, nil), 3)
+
+	actual := AskedQuestions(questions, []Answer{{QuestionID: 12}, {QuestionID: 10}, {QuestionID: 12}})
+	assert.Len(t, actual, 2)
+	assert.Equal(t, uint(10), actual[0].ID)
+	assert.Equal(t, uint(12), actual[1].ID)
+}
@@ -0,0 +1,103 @@
+package entity
+
+import "time"
+
+// Review is one player's look back at a finished instance: what they picked for each question against the
+// correct options and the rest of the room.
+type Review struct {
+	InstanceID uint              `json:"instance_id"`
+	GameID     uint              `json:"game_id"`
+	Topic      string            `json:"topic"`
+	StartedAt  time.Time         `json:"started_at"`
+	EndedAt    time.Time         `json:"ended_at"`
+	Rank       int               `json:"rank"`
+	Points     float64           `json:"points"`
+	Correct    int               `json:"correct"`
+	Questions  []*QuestionReview `json:"questions"`
+}
+
+type QuestionReview struct {
+	QuestionID   uint            `json:"question_id"`
+	Name         string          `json:"name"`
+	Explanation  string          `json:"explanation"`
+	Options      []*OptionReview `json:"options"`
+	Picked       uint            `json:"picked"`
+	Correct      bool            `json:"correct"`
+	Points       float64         `json:"points"`
+	ResponseTime int64           `json:"response_time"`
+	Answers      int             `json:"answers"`
+}
+
+// OptionReview is an option with how many players in the room picked it.
+type OptionReview struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Correct bool   `json:"correct"`
+	Picks   int    `json:"picks"`
+}
+
+// NewReview builds userID's review of instance from its leaderboard, which must be ordered by points, and
+// every answer recorded during it. Picked is zero for questions the player left unanswered.
+func NewReview(instance *GameInstance, leaderboard []Leaderboard, answers []Answer, userID uint) *Review {
+	review := &Review{
+		InstanceID: instance.ID,
+		GameID:     instance.GameID,
+		Topic:      instance.Game.Topic,
+		StartedAt:  instance.StartedAt,
+		EndedAt:    instance.EndedAt,
+		Questions:  []*QuestionReview{},
+	}
+
+	for _, entry := range RankLeaderboard(leaderboard, instance.HostID) {
+		if entry.UserID == userID {
+			review.Rank = entry.Rank
+			review.Points = entry.Points
+		}
+	}
+
+	picks := make(map[uint]int)
+	answered := make(map[uint]int)
+	own := make(map[uint]Answer)
+	for _, answer := range answers {
+		if answer.OptionID != 0 {
+			picks[answer.OptionID]++
+			answered[answer.QuestionID]++
+		}
+		if answer.UserID == userID {
+			own[answer.QuestionID] = answer
+		}
+	}
+
+	for _, question := range AskedQuestions(instance.Game.Questions, answers) {
+		result := &QuestionReview{
+			QuestionID:  question.ID,
+			Name:        question.Name,
+			Explanation: question.Explanation,
+			Options:     []*OptionReview{},
+			Answers:     answered[question.ID],
+		}
+
+		for _, option := range question.Options {
+			result.Options = append(result.Options, &OptionReview{
+				ID:      option.ID,
+				Name:    option.Name,
+				Correct: option.Correct,
+				Picks:   picks[option.ID],
+			})
+		}
+
+		if answer, ok := own[question.ID]; ok {
+			result.Picked = answer.OptionID
+			result.Correct = answer.Correct
+			result.Points = answer.Points
+			result.ResponseTime = answer.ResponseTime
+		}
+		if result.Correct {
+			review.Correct++
+		}
+
+		review.Questions = append(review.Questions, result)
+	}
+
+	return review
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReview(t *testing.T) {
+	instance := &GameInstance{
+		ID:     7,
+		GameID: 1,
+		HostID: 1,
+		Game: Game{
+			ID:    1,
+			Topic: "Colors",
+			Questions: []*Question{
+				{ID: 10, Name: "Tomato?", Explanation: "Ripe ones are.", Options: []*Option{{ID: 100, Name: "Red", Correct: true}, {ID: 101, Name: "Green"}}},
+				{ID: 11, Name: "Grass?", Options: []*Option{{ID: 110, Name: "Red"}, {ID: 111, Name: "Green", Correct: true}}},
+			},
+		},
+	}
+
+	leaderboard := []Leaderboard{
+		{UserID: 2, Name: "Alice", Points: 6},
+		{UserID: 3, Name: "Bob", Points: 3},
+		{UserID: 1, Name: "Host", Points: 0},
+	}
+
+	answers := []Answer{
+		{UserID: 2, QuestionID: 10, OptionID: 100, Correct: true, Points: 3},
+		{UserID: 2, QuestionID: 11, OptionID: 111, Correct: true, Points: 3},
+		{UserID: 3, QuestionID: 10, OptionID: 101, Correct: false, ResponseTime: 1500},
+		{UserID: 3, QuestionID: 11},
+	}
+
+	actual := NewReview(instance, leaderboard, answers, 3)
+
+	assert.Equal(t, 2, actual.Rank)
+	assert.Equal(t, float64(3), actual.Points)
+	assert.Equal(t, 0, actual.Correct)
+	assert.Len(t, actual.Questions, 2)
+
+	first := actual.Questions[0]
+	assert.Equal(t, "Ripe ones are.", first.Explanation)
+	assert.Equal(t, uint(101), first.Picked)
+	assert.Equal(t, int64(1500), first.ResponseTime)
+	assert.Equal(t, 2, first.Answers)
+	assert.Equal(t, 1, first.Options[0].Picks)
+	assert.True(t, first.Options[0].Correct)
+	assert.Equal(t, 1, first.Options[1].Picks)
+
+	second := actual.Questions[1]
+	assert.Equal(t, uint(0), second.Picked)
+	assert.Equal(t, 1, second.Answers)
+	assert.Equal(t, 1, second.Options[1].Picks)
+}
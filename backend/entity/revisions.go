@@ -0,0 +1,229 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStaleRevision refuses a change made to a revision of a game that is no longer its current one.
+var ErrStaleRevision = errors.New("game has changed since it was loaded")
+
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// GameRevision is an immutable snapshot of a game's content, numbered from 1 in the order the game was saved.
+// Instances remember the revision they played, so their results survive later edits. Content is left out
+// of revision listings.
+type GameRevision struct {
+	ID        uint             `json:"id" gorm:"primary_key"`
+	GameID    uint             `json:"game_id" gorm:"uniqueIndex:idx_game_revision"`
+	Number    int              `json:"number" gorm:"uniqueIndex:idx_game_revision"`
+	AuthorID  uint             `json:"author_id"`
+	Content   *Snapshot        `json:"content,omitempty"`
+	Changes   []RevisionChange `json:"changes" gorm:"-"`
+	CreatedAt time.Time        `json:"created_at" gorm:"default:current_timestamp"`
+}
+
+// Snapshot is a game's settings and questions at one revision, stored as JSON.
+type Snapshot struct {
+	Topic            string      `json:"topic"`
+	RoundTime        int         `json:"round_time"`
+	Points           float64     `json:"points"`
+	Public           bool        `json:"public"`
+	ShuffleQuestions bool        `json:"shuffle_questions"`
+	ShuffleOptions   bool        `json:"shuffle_options"`
+	PoolSize         int         `json:"pool_size"`
+	Questions        []*Question `json:"questions"`
+}
+
+// RevisionChange is one difference between two revisions. Field names the setting or question field that
+// changed, and is empty when a whole question was added or removed.
+type RevisionChange struct {
+	Kind       string `json:"kind"`
+	QuestionID uint   `json:"question_id,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Before     any    `json:"before"`
+	After      any    `json:"after"`
+}
+
+func (s Snapshot) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(s)
+	return string(bytes), err
+}
+
+func (s *Snapshot) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case []byte:
+		return json.Unmarshal(v, s)
+	default:
+		return fmt.Errorf("cannot scan %T into snapshot", value)
+	}
+}
+
+func (Snapshot) GormDataType() string {
+	return "text"
+}
+
+// NewRevision snapshots game as its current revision.
+func NewRevision(game *Game, authorID uint) *GameRevision {
+	content := &Snapshot{
+		Topic:            game.Topic,
+		RoundTime:        game.RoundTime,
+		Points:           game.Points,
+		Public:           game.Public,
+		ShuffleQuestions: game.ShuffleQuestions,
+		ShuffleOptions:   game.ShuffleOptions,
+		PoolSize:         game.PoolSize,
+	}
+
+	for _, q := range game.Questions {
+		question := *q
+		question.Options = nil
+		for _, o := range q.Options {
+			option := *o
+			question.Options = append(question.Options, &option)
+		}
+		content.Questions = append(content.Questions, &question)
+	}
+
+	return &GameRevision{
+		GameID:   game.ID,
+		Number:   game.Revision,
+		AuthorID: authorID,
+		Content:  content,
+	}
+}
+
+// Apply shows game as it was at this snapshot, questions and their IDs included. It is meant for reading
+// old content; use Restore to save it again.
+func (s Snapshot) Apply(game *Game) {
+	s.settings(game)
+	game.Questions = s.Questions
+}
+
+// Restore puts the snapshot's content back into game for saving. Questions still in game with as many
+// options are edited in place so their history stays linked, the rest are recreated. It returns the IDs of
+// the questions in game that the snapshot does not have, which the caller deletes.
+func (s Snapshot) Restore(game *Game) []uint {
+	s.settings(game)
+
+	current := make(map[uint]*Question)
+	for _, q := range game.Questions {
+		current[q.ID] = q
+	}
+
+	kept := make(map[uint]bool)
+	var questions []*Question
+	for _, q := range s.Questions {
+		existing, ok := current[q.ID]
+		if ok && len(existing.Options) == len(q.Options) {
+			existing.Name = q.Name
+			existing.Explanation = q.Explanation
+			existing.BankQuestionID = q.BankQuestionID
+			for i, o := range q.Options {
+				existing.Options[i].Name = o.Name
+				existing.Options[i].Correct = o.Correct
+			}
+			kept[q.ID] = true
+			questions = append(questions, existing)
+			continue
+		}
+
+		question := &Question{Name: q.Name, Explanation: q.Explanation, BankQuestionID: q.BankQuestionID}
+		for _, o := range q.Options {
+			question.Options = append(question.Options, &Option{Name: o.Name, Correct: o.Correct})
+		}
+		questions = append(questions, question)
+	}
+
+	var removed []uint
+	for _, q := range game.Questions {
+		if !kept[q.ID] {
+			removed = append(removed, q.ID)
+		}
+	}
+
+	game.Questions = questions
+	return removed
+}
+
+func (s Snapshot) settings(game *Game) {
+	game.Topic = s.Topic
+	game.RoundTime = s.RoundTime
+	game.Points = s.Points
+	game.Public = s.Public
+	game.ShuffleQuestions = s.ShuffleQuestions
+	game.ShuffleOptions = s.ShuffleOptions
+	game.PoolSize = s.PoolSize
+}
+
+// DiffRevisions lists what changed from before to after. Questions are matched by ID.
+func DiffRevisions(before, after *Snapshot) []RevisionChange {
+	changes := []RevisionChange{}
+	setting := func(field string, b, a any) {
+		if b != a {
+			changes = append(changes, RevisionChange{Kind: ChangeChanged, Field: field, Before: b, After: a})
+		}
+	}
+
+	setting("topic", before.Topic, after.Topic)
+	setting("round_time", before.RoundTime, after.RoundTime)
+	setting("points", before.Points, after.Points)
+	setting("public", before.Public, after.Public)
+	setting("shuffle_questions", before.ShuffleQuestions, after.ShuffleQuestions)
+	setting("shuffle_options", before.ShuffleOptions, after.ShuffleOptions)
+	setting("pool_size", before.PoolSize, after.PoolSize)
+
+	old := make(map[uint]*Question)
+	for _, q := range before.Questions {
+		old[q.ID] = q
+	}
+	seen := make(map[uint]bool)
+
+	for _, q := range after.Questions {
+		b, ok := old[q.ID]
+		if !ok {
+			changes = append(changes, RevisionChange{Kind: ChangeAdded, QuestionID: q.ID, After: q})
+			continue
+		}
+		seen[q.ID] = true
+
+		if b.Name != q.Name {
+			changes = append(changes, RevisionChange{Kind: ChangeChanged, QuestionID: q.ID, Field: "name", Before: b.Name, After: q.Name})
+		}
+		if b.Explanation != q.Explanation {
+			changes = append(changes, RevisionChange{Kind: ChangeChanged, QuestionID: q.ID, Field: "explanation", Before: b.Explanation, After: q.Explanation})
+		}
+		if !sameOptions(b.Options, q.Options) {
+			changes = append(changes, RevisionChange{Kind: ChangeChanged, QuestionID: q.ID, Field: "options", Before: b.Options, After: q.Options})
+		}
+	}
+
+	for _, q := range before.Questions {
+		if !seen[q.ID] {
+			changes = append(changes, RevisionChange{Kind: ChangeRemoved, QuestionID: q.ID, Before: q})
+		}
+	}
+
+	return changes
+}
+
+func sameOptions(a, b []*Option) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Correct != b[i].Correct {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func revisionGame() *Game {
+	return &Game{
+		ID:        1,
+		Topic:     "My game",
+		RoundTime: 10,
+		Points:    3,
+		Revision:  2,
+		Questions: []*Question{
+			{ID: 4, Name: "What color is tomato?", Options: []*Option{{ID: 5, Name: "Red", Correct: true}, {ID: 6, Name: "Green"}}},
+			{ID: 7, Name: "What color is grass?", Options: []*Option{{ID: 8, Name: "Red"}, {ID: 9, Name: "Green", Correct: true}}},
+		},
+	}
+}
+
+func TestNewRevision(t *testing.T) {
+	game := revisionGame()
+
+	actual := NewRevision(game, 3)
+	game.Questions[0].Name = "Edited"
+	game.Questions[0].Options[0].Name = "Edited"
+
+	assert.Equal(t, uint(1), actual.GameID)
+	assert.Equal(t, 2, actual.Number)
+	assert.Equal(t, uint(3), actual.AuthorID)
+	assert.Equal(t, "What color is tomato?", actual.Content.Questions[0].Name)
+	assert.Equal(t, "Red", actual.Content.Questions[0].Options[0].Name)
+}
+
+func TestDiffRevisions(t *testing.T) {
+	before := NewRevision(revisionGame(), 1).Content
+
+	game := revisionGame()
+	game.Topic = "Colors"
+	game.Questions[0].Options[1].Correct = true
+	game.Questions = append(game.Questions[:1], &Question{ID: 10, Name: "What color is the sky?"})
+	after := NewRevision(game, 1).Content
+
+	actual := DiffRevisions(before, after)
+
+	assert.Equal(t, []RevisionChange{
+		{Kind: ChangeChanged, Field: "topic", Before: "My game", After: "Colors"},
+		{Kind: ChangeChanged, QuestionID: 4, Field: "options", Before: before.Questions[0].Options, After: after.Questions[0].Options},
+		{Kind: ChangeAdded, QuestionID: 10, After: after.Questions[1]},
+		{Kind: ChangeRemoved, QuestionID: 7, Before: before.Questions[1]},
+	}, actual)
+
+	assert.Empty(t, DiffRevisions(before, before))
+}
+
+func TestRestoreRevision(t *testing.T) {
+	old := NewRevision(revisionGame(), 1).Content
+
+	game := revisionGame()
+	game.Topic = "Colors"
+	game.Questions[0].Name = "Edited"
+	game.Questions[1].Options = append(game.Questions[1].Options, &Option{ID: 11}, &Option{ID: 12})
+	game.Questions = append(game.Questions, &Question{ID: 10, Name: "New"})
+
+	removed := old.Restore(game)
+
+	assert.Equal(t, "My game", game.Topic)
+	assert.Len(t, game.Questions, 2)
+	assert.Equal(t, uint(4), game.Questions[0].ID)
+	assert.Equal(t, "What color is tomato?", game.Questions[0].Name)
+	assert.Zero(t, game.Questions[1].ID)
+	assert.Equal(t, "What color is grass?", game.Questions[1].Name)
+	assert.Len(t, game.Questions[1].Options, 2)
+	assert.ElementsMatch(t, []uint{7, 10}, removed)
+}
+
+func TestApplyRevision(t *testing.T) {
+	old := NewRevision(revisionGame(), 1).Content
+
+	game := revisionGame()
+	game.Topic = "Colors"
+	game.Questions = game.Questions[:1]
+
+	old.Apply(game)
+
+	assert.Equal(t, "My game", game.Topic)
+	assert.Len(t, game.Questions, 2)
+	assert.Equal(t, uint(7), game.Questions[1].ID)
+}
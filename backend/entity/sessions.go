@@ -5,25 +5,58 @@ import (
 )
 
 type GameSession struct {
-	ID          uint           `json:"id" gorm:"primary_key"`
-	GameID      uint           `json:"game_id"`
-	UserID      uint           `json:"user_id"`
-	InstanceID  uint           `json:"-"`
-	Points      float64        `json:"points"`
-	Questions   int            `json:"questions"`
-	Players     int            `json:"players"`
-	Game        Game           `json:"game"`
-	Leaderboard *[]Leaderboard `json:"leaderboard" gorm:"-"`
-	StartedAt   time.Time      `json:"started_at" gorm:"default:current_timestamp"`
-	EndedAt     time.Time      `json:"ended_at"`
+	ID          uint          `json:"id" gorm:"primary_key"`
+	GameID      uint          `json:"game_id"`
+	UserID      uint          `json:"user_id"`
+	InstanceID  uint          `json:"instance_id"`
+	Points      float64       `json:"points"`
+	Questions   int           `json:"questions"`
+	Players     int           `json:"players"`
+	Game        Game          `json:"game"`
+	Host        bool          `json:"host" gorm:"-"`
+	Rank        int           `json:"rank" gorm:"-"`
+	Leaderboard []Leaderboard `json:"leaderboard" gorm:"-"`
+	StartedAt   time.Time     `json:"started_at" gorm:"default:current_timestamp"`
+	EndedAt     time.Time     `json:"ended_at"`
+}
+
+// GameInstance is a single run of a game, hosted by the user who started it. Revision is the revision of
+// the game it played, or zero for instances that predate revisions.
+type GameInstance struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	GameID    uint      `json:"game_id"`
+	Revision  int       `json:"revision"`
+	HostID    uint      `json:"host_id"`
+	Questions int       `json:"questions"`
+	Players   int       `json:"players"`
+	Game      Game      `json:"game"`
+	StartedAt time.Time `json:"started_at" gorm:"default:current_timestamp"`
+	EndedAt   time.Time `json:"ended_at"`
 }
 
 type Leaderboard struct {
+	Rank   int     `json:"rank"`
 	Name   string  `json:"name"`
 	UserID uint    `json:"user_id"`
 	Points float64 `json:"points"`
 }
 
+// SessionFilter narrows down a user's session history. Cursor is the ID of the last session of the previous page,
+// and PublicOnly keeps the sessions of public quizzes.
+type SessionFilter struct {
+	Cursor     uint
+	Limit      int
+	GameID     uint
+	From       time.Time
+	To         time.Time
+	PublicOnly bool
+}
+
+type SessionPage struct {
+	Sessions   []GameSession `json:"sessions"`
+	NextCursor uint          `json:"next_cursor,omitempty"`
+}
+
 func NewSession(gameID, userID, instID uint) *GameSession {
 	session := &GameSession{
 		GameID:     gameID,
@@ -33,3 +66,48 @@ func NewSession(gameID, userID, instID uint) *GameSession {
 	}
 	return session
 }
+
+func NewInstance(gameID uint, revision int, hostID, instID uint) *GameInstance {
+	instance := &GameInstance{
+		ID:        instID,
+		GameID:    gameID,
+		Revision:  revision,
+		HostID:    hostID,
+		StartedAt: time.Now(),
+	}
+	return instance
+}
+
+// SetLeaderboard ranks the leaderboard of the session's instance without its host and records
+// where the session's user placed in it.
+func (s *GameSession) SetLeaderboard(leaderboard []Leaderboard, hostID uint) {
+	s.Host = s.UserID == hostID
+	s.Leaderboard = RankLeaderboard(leaderboard, hostID)
+
+	for _, entry := range s.Leaderboard {
+		if entry.UserID == s.UserID {
+			s.Rank = entry.Rank
+		}
+	}
+}
+
+// RankLeaderboard drops the host from leaderboard, which must be ordered by points, and ranks the rest.
+// Players with the same points share a rank.
+func RankLeaderboard(leaderboard []Leaderboard, hostID uint) []Leaderboard {
+	ranked := []Leaderboard{}
+
+	for _, entry := range leaderboard {
+		if entry.UserID == hostID {
+			continue
+		}
+
+		entry.Rank = len(ranked) + 1
+		if previous := len(ranked) - 1; previous >= 0 && ranked[previous].Points == entry.Points {
+			entry.Rank = ranked[previous].Rank
+		}
+
+		ranked = append(ranked, entry)
+	}
+
+	return ranked
+}
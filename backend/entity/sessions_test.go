@@ -19,3 +19,46 @@ func TestNewSession(t *testing.T) {
 	assert.Equal(t, wantGameID, actual.GameID)
 	assert.Equal(t, wantUserID, actual.UserID)
 }
+
+func TestNewInstance(t *testing.T) {
+	// Given
+	wantGameID := uint(1)
+	wantRevision := 4
+	wantHostID := uint(2)
+	wantInstID := uint(3)
+
+	// When
+	actual := NewInstance(wantGameID, wantRevision, wantHostID, wantInstID)
+
+	// Then
+	assert.Equal(t, wantInstID, actual.ID)
+	assert.Equal(t, wantGameID, actual.GameID)
+	assert.Equal(t, wantRevision, actual.Revision)
+	assert.Equal(t, wantHostID, actual.HostID)
+}
+
+func TestSetLeaderboard(t *testing.T) {
+	// Given
+	leaderboard := []Leaderboard{
+		{UserID: 2, Points: 9},
+		{UserID: 3, Points: 6},
+		{UserID: 4, Points: 6},
+		{UserID: 5, Points: 3},
+		{UserID: 1, Points: 0},
+	}
+	session := NewSession(1, 4, 1)
+
+	// When
+	session.SetLeaderboard(leaderboard, 1)
+
+	// Then
+	assert.Len(t, session.Leaderboard, 4)
+	assert.Equal(t, 2, session.Rank)
+	assert.False(t, session.Host)
+	assert.Equal(t, []int{1, 2, 2, 4}, []int{
+		session.Leaderboard[0].Rank,
+		session.Leaderboard[1].Rank,
+		session.Leaderboard[2].Rank,
+		session.Leaderboard[3].Rank,
+	})
+}
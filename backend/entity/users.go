@@ -6,12 +6,37 @@ import (
 )
 
 type User struct {
-	ID         uint   `json:"id" gorm:"primary_key"`
-	GoogleID   string `json:"-"`
-	DiscordID  string `json:"-"`
-	TelegramID string `json:"-"`
-	Picture    string `json:"picture"`
-	Name       string `json:"name"`
+	ID            uint           `json:"id" gorm:"primary_key"`
+	GoogleID      string         `json:"-"`
+	DiscordID     string         `json:"-"`
+	TelegramID    string         `json:"-"`
+	Picture       string         `json:"picture"`
+	Name          string         `json:"name"`
+	Rating        float64        `json:"rating" gorm:"default:1200"`
+	RatedGames    int            `json:"rated_games"`
+	XP            int64          `json:"xp"`
+	Level         LevelProgress  `json:"level" gorm:"-"`
+	PrivateStats  bool           `json:"private_stats"`
+	Stats         *ProfileStats  `json:"stats,omitempty" gorm:"-"`
+	RatingHistory []RatingChange `json:"rating_history,omitempty" gorm:"-"`
+	Achievements  []Achievement  `json:"achievements,omitempty" gorm:"-"`
+}
+
+// PrivateProfile is what other users see of a user with private stats.
+type PrivateProfile struct {
+	ID           uint   `json:"id"`
+	Picture      string `json:"picture"`
+	Name         string `json:"name"`
+	PrivateStats bool   `json:"private_stats"`
+}
+
+// ShowsStatsTo reports whether viewerID may see the user's rating, XP, level and play history.
+func (u *User) ShowsStatsTo(viewerID uint) bool {
+	return !u.PrivateStats || u.ID == viewerID
+}
+
+func (u *User) PrivateProfile() *PrivateProfile {
+	return &PrivateProfile{ID: u.ID, Picture: u.Picture, Name: u.Name, PrivateStats: u.PrivateStats}
 }
 
 type CreateUser struct {
@@ -23,8 +48,9 @@ type CreateUser struct {
 }
 
 type UpdateUser struct {
-	Picture string `json:"picture"`
-	Name    string `json:"name"`
+	Picture      string `json:"picture"`
+	Name         string `json:"name"`
+	PrivateStats *bool  `json:"private_stats"`
 }
 
 type GoogleUser struct {
@@ -54,6 +80,7 @@ func NewUser(body *CreateUser) (*User, error) {
 		TelegramID: body.TelegramID,
 		Picture:    body.Picture,
 		Name:       body.Name,
+		Rating:     DefaultRating,
 	}
 
 	if err := user.Validate(); err != nil {
@@ -36,3 +36,14 @@ func TestNewUser(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestPrivateStats(t *testing.T) {
+	user := &User{ID: 1, Name: "test", Rating: 1300, XP: 250, PrivateStats: true}
+
+	assert.True(t, user.ShowsStatsTo(1))
+	assert.False(t, user.ShowsStatsTo(2))
+	assert.Equal(t, &PrivateProfile{ID: 1, Name: "test", PrivateStats: true}, user.PrivateProfile())
+
+	user.PrivateStats = false
+	assert.True(t, user.ShowsStatsTo(2))
+}
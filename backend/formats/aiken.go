@@ -0,0 +1,80 @@
+package formats
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+var (
+	aikenOption = regexp.MustCompile(`^([A-Z])[.)]\s+(.*)$`)
+	aikenAnswer = regexp.MustCompile(`^ANSWER:\s*([A-Z])\s*$`)
+)
+
+// ParseAiken reads the Aiken format: a question line, one line per option starting with its letter and a
+// dot or parenthesis, and an ANSWER: line naming the correct letter. Questions are separated by blank lines.
+func ParseAiken(r io.Reader) (*Parsed, error) {
+	parsed := &Parsed{}
+	scanner := bufio.NewScanner(r)
+
+	var question *entity.CreateQuestion
+	var letters []string
+	start := 0
+
+	// flush ends the current question, reporting message unless it is empty.
+	flush := func(message string) {
+		if question != nil && message != "" {
+			parsed.fail(start, message)
+		}
+		question = nil
+		letters = nil
+	}
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush("missing ANSWER line")
+		case question == nil:
+			question = &entity.CreateQuestion{Name: line}
+			start = n
+		case aikenOption.MatchString(line):
+			match := aikenOption.FindStringSubmatch(line)
+			question.Options = append(question.Options, entity.CreateOption{Name: match[2]})
+			letters = append(letters, match[1])
+		case aikenAnswer.MatchString(line):
+			letter := aikenAnswer.FindStringSubmatch(line)[1]
+
+			found := false
+			for i := range question.Options {
+				if letters[i] == letter {
+					question.Options[i].Correct = true
+					found = true
+				}
+			}
+
+			if found {
+				parsed.add(start, *question)
+				flush("")
+			} else {
+				flush("answer " + letter + " is not one of the options")
+			}
+		case len(question.Options) == 0:
+			// Question text may run over several lines until the first option.
+			question.Name += " " + line
+		default:
+			flush("expected an option or an ANSWER line")
+			for scanner.Scan() && strings.TrimSpace(scanner.Text()) != "" {
+				n++
+			}
+			n++
+		}
+	}
+	flush("missing ANSWER line")
+
+	return parsed, scanner.Err()
+}
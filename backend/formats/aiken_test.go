@@ -0,0 +1,42 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ip-05/quizzus/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAiken(t *testing.T) {
+	t.Run("TestValid", func(t *testing.T) {
+		doc := "What color is tomato?\nA. Red\nB) Green\nANSWER: A\n\nWhich planet is\nthe largest?\nA. Mars\nB. Venus\nC. Jupiter\nD. Earth\nANSWER: C\n"
+
+		actual, err := ParseAiken(strings.NewReader(doc))
+
+		assert.Nil(t, err)
+		assert.Empty(t, actual.Errors)
+		assert.Len(t, actual.Questions, 2)
+		assert.Equal(t, "Which planet is the largest?", actual.Questions[1].Name)
+		assert.True(t, actual.Questions[0].Options[0].Correct)
+		assert.True(t, actual.Questions[1].Options[2].Correct)
+	})
+
+	t.Run("TestErrors", func(t *testing.T) {
+		doc := "Missing answer\nA. One\nB. Two\n\nUnknown answer\nA. One\nB. Two\nANSWER: C\n\nWhat color is tomato?\nA. Red\nB. Green\nANSWER: A\n"
+
+		actual, err := ParseAiken(strings.NewReader(doc))
+
+		assert.Nil(t, err)
+		assert.Len(t, actual.Questions, 1)
+		assert.Equal(t, []int{1, 5}, lines(actual.Errors))
+	})
+}
+
+func lines(errs []entity.ImportError) []int {
+	var lines []int
+	for _, err := range errs {
+		lines = append(lines, err.Line)
+	}
+	return lines
+}
@@ -0,0 +1,136 @@
+package formats
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+// CSVHeader is the header row of the CSV layout.
+var CSVHeader = []string{"question", "option_a", "option_b", "option_c", "option_d", "correct", "explanation"}
+
+// ParseCSV reads the CSV layout: a CSVHeader row, then one question per row. Questions have two or four
+// options, so option_c and option_d are either both set or both empty. correct lists the letters of the
+// correct options separated by semicolons, e.g. "A" or "A;C". explanation may be empty.
+func ParseCSV(r io.Reader) (*Parsed, error) {
+	parsed := &Parsed{}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	// The header fixes the number of fields every row must have.
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("missing header row")
+	}
+	if len(header) != len(CSVHeader) {
+		return nil, errors.New("header must be " + strings.Join(CSVHeader, ","))
+	}
+	for i, column := range CSVHeader {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), column) {
+			return nil, errors.New("header must be " + strings.Join(CSVHeader, ","))
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			parsed.fail(parseErr.StartLine, parseErr.Err.Error())
+			if parseErr.Err == csv.ErrFieldCount {
+				continue
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		question := entity.CreateQuestion{
+			Name:        strings.TrimSpace(record[0]),
+			Explanation: strings.TrimSpace(record[6]),
+		}
+		options, message := csvOptions(record[1:5])
+		if message == "" {
+			message = markCorrect(options, record[5])
+		}
+		if message != "" {
+			parsed.fail(line, message)
+			continue
+		}
+
+		question.Options = options
+		parsed.add(line, question)
+	}
+
+	return parsed, nil
+}
+
+// csvOptions collects the option columns, which are filled in from option_a.
+func csvOptions(names []string) ([]entity.CreateOption, string) {
+	var options []entity.CreateOption
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if i != len(options) {
+			return nil, "options must be filled in from option_a"
+		}
+		options = append(options, entity.CreateOption{Name: name})
+	}
+	return options, ""
+}
+
+// markCorrect sets the options named by letters such as "A;C", or explains why it cannot.
+func markCorrect(options []entity.CreateOption, letters string) string {
+	for _, letter := range strings.Split(letters, ";") {
+		letter = strings.ToUpper(strings.TrimSpace(letter))
+		if len(letter) != 1 || letter[0] < 'A' || int(letter[0]-'A') >= len(options) {
+			return "correct must list option letters, e.g. A or A;C"
+		}
+		options[letter[0]-'A'].Correct = true
+	}
+	return ""
+}
+
+// WriteCSV writes game in the CSV layout. The layout has no room for the game's settings.
+func WriteCSV(w io.Writer, game *entity.Game) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	for _, q := range game.Questions {
+		record := make([]string, len(CSVHeader))
+		record[0] = q.Name
+		record[6] = q.Explanation
+
+		var correct []string
+		for i, o := range q.Options {
+			if i >= 4 {
+				return errors.New("the CSV layout holds at most 4 options")
+			}
+			record[i+1] = o.Name
+			if o.Correct {
+				correct = append(correct, string(rune('A'+i)))
+			}
+		}
+		record[5] = strings.Join(correct, ";")
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
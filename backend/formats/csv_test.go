@@ -0,0 +1,45 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("TestValid", func(t *testing.T) {
+		doc := "question,option_a,option_b,option_c,option_d,correct,explanation\n" +
+			"What color is tomato?,Red,Green,,,A,Ripe ones are red.\n" +
+			"\"Pick the primes, all of them\",2,3,4,6,A;B,\n"
+
+		actual, err := ParseCSV(strings.NewReader(doc))
+
+		assert.Nil(t, err)
+		assert.Empty(t, actual.Errors)
+		assert.Len(t, actual.Questions, 2)
+		assert.Equal(t, "Ripe ones are red.", actual.Questions[0].Explanation)
+		assert.Equal(t, "Pick the primes, all of them", actual.Questions[1].Name)
+		assert.True(t, actual.Questions[1].Options[1].Correct)
+		assert.False(t, actual.Questions[1].Options[2].Correct)
+	})
+
+	t.Run("TestErrors", func(t *testing.T) {
+		doc := "question,option_a,option_b,option_c,option_d,correct,explanation\n" +
+			"Too few columns,Red,Green\n" +
+			"Bad letter,Red,Green,,,C,\n" +
+			"Gap,Red,,Blue,,A,\n" +
+			"What color is tomato?,Red,Green,,,A,\n"
+
+		actual, err := ParseCSV(strings.NewReader(doc))
+
+		assert.Nil(t, err)
+		assert.Len(t, actual.Questions, 1)
+		assert.Equal(t, []int{2, 3, 4}, lines(actual.Errors))
+	})
+
+	t.Run("TestBadHeader", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("name,a,b\n"))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,71 @@
+package formats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ip-05/quizzus/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	game := &entity.Game{
+		Topic:          "Science",
+		RoundTime:      30,
+		Points:         20,
+		ShuffleOptions: true,
+		Questions: []*entity.Question{
+			{
+				Name:        "Which planet is the largest? {hint: not Earth}",
+				Explanation: "Jupiter is over 300 times as heavy as Earth.\nEven Saturn is far lighter.",
+				Options:     []*entity.Option{{Name: "Mars"}, {Name: "Jupiter", Correct: true}, {Name: "Venus"}, {Name: "Earth = home"}},
+			},
+			{
+				Name:    "Pick the primes",
+				Options: []*entity.Option{{Name: "2", Correct: true}, {Name: "3", Correct: true}, {Name: "4"}, {Name: "6"}},
+			},
+			{
+				Name:    "Pick all of them, 100% sure",
+				Options: []*entity.Option{{Name: "a", Correct: true}, {Name: "b", Correct: true}, {Name: "c", Correct: true}, {Name: "d"}},
+			},
+		},
+	}
+
+	for _, format := range []string{JSON, GIFT, CSV, QTI} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.Nil(t, Export(format, &buf, game))
+
+			parsed, err := Parse(format, &buf)
+
+			assert.Nil(t, err)
+			assert.Empty(t, parsed.Errors)
+			assert.Len(t, parsed.Questions, len(game.Questions))
+			for i, q := range game.Questions {
+				assert.Equal(t, q.Name, parsed.Questions[i].Name)
+				assert.Equal(t, q.Explanation, parsed.Questions[i].Explanation)
+				for j, o := range q.Options {
+					assert.Equal(t, o.Name, parsed.Questions[i].Options[j].Name)
+					assert.Equal(t, o.Correct, parsed.Questions[i].Options[j].Correct)
+				}
+			}
+		})
+	}
+
+	t.Run("TestSettings", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, WriteJSON(&buf, game))
+
+		parsed, err := ParseJSON(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Science", parsed.Settings.Topic)
+		assert.Equal(t, 30, parsed.Settings.RoundTime)
+		assert.True(t, parsed.Settings.ShuffleOptions)
+	})
+
+	t.Run("TestUnknownVersion", func(t *testing.T) {
+		_, err := ParseJSON(bytes.NewBufferString(`{"format": "quizzus", "version": 99}`))
+		assert.NotNil(t, err)
+	})
+}
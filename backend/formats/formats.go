@@ -0,0 +1,123 @@
+// Package formats converts quizzes to and from the formats other quiz tools exchange them in.
+package formats
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+const (
+	JSON  = "json"
+	GIFT  = "gift"
+	Aiken = "aiken"
+	CSV   = "csv"
+	QTI   = "qti"
+)
+
+// Parsed is what a parser made of a document. Questions that could not be read are left out and reported
+// in Errors, questions of a type games do not have are left out with a warning. Title is set when the
+// document names its quiz, and Settings when it carries the rest of the game's settings too.
+type Parsed struct {
+	Title     string
+	Settings  *entity.CreateGame
+	Questions []entity.CreateQuestion
+	Errors    []entity.ImportError
+	Warnings  []entity.ImportError
+}
+
+// add keeps question if it is valid, or records why it was left out.
+func (p *Parsed) add(line int, question entity.CreateQuestion) {
+	if err := check(question); err != nil {
+		p.fail(line, err.Error())
+		return
+	}
+
+	p.Questions = append(p.Questions, question)
+}
+
+func (p *Parsed) fail(line int, message string) {
+	p.Errors = append(p.Errors, entity.ImportError{Line: line, Message: message})
+}
+
+func (p *Parsed) warn(line int, message string) {
+	p.Warnings = append(p.Warnings, entity.ImportError{Line: line, Message: message})
+}
+
+// check validates question the way games do.
+func check(question entity.CreateQuestion) error {
+	if _, err := entity.NewQuestion(question); err != nil {
+		return err
+	}
+
+	for _, option := range question.Options {
+		if option.Correct {
+			return nil
+		}
+	}
+	return errors.New("no correct option")
+}
+
+// unsupported marks errors for question types games do not have.
+type unsupported string
+
+func (u unsupported) Error() string {
+	return string(u)
+}
+
+// Parse reads a document in format.
+func Parse(format string, r io.Reader) (*Parsed, error) {
+	switch format {
+	case JSON:
+		return ParseJSON(r)
+	case GIFT:
+		return ParseGIFT(r)
+	case Aiken:
+		return ParseAiken(r)
+	case CSV:
+		return ParseCSV(r)
+	case QTI:
+		return ParseQTI(r)
+	default:
+		return nil, errors.New("format must be json, gift, aiken, csv or qti")
+	}
+}
+
+// Export writes game to w in format.
+func Export(format string, w io.Writer, game *entity.Game) error {
+	switch format {
+	case JSON:
+		return WriteJSON(w, game)
+	case GIFT:
+		return WriteGIFT(w, game)
+	case CSV:
+		return WriteCSV(w, game)
+	case QTI:
+		return WriteQTI(w, game)
+	default:
+		return errors.New("format must be json, gift, csv or qti")
+	}
+}
+
+// ContentType is the media type of documents in format.
+func ContentType(format string) string {
+	switch format {
+	case JSON:
+		return "application/json"
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case QTI:
+		return "application/zip"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension is the file extension of documents in format.
+func Extension(format string) string {
+	if format == QTI {
+		return "zip"
+	}
+	return format
+}
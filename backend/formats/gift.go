@@ -0,0 +1,225 @@
+package formats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+var (
+	giftFormat = regexp.MustCompile(`^\[(html|moodle|markdown|plain)\]`)
+	giftWeight = regexp.MustCompile(`^%(-?[0-9.]+)%`)
+	giftQuote  = strings.NewReplacer(`\`, `\\`, "~", `\~`, "=", `\=`, "#", `\#`, "{", `\{`, "}", `\}`, ":", `\:`, "\n", `\n`)
+	giftEscape = strings.NewReplacer(`\~`, "~", `\=`, "=", `\#`, "#", `\{`, "{", `\}`, "}", `\:`, ":", `\n`, "\n", `\\`, `\`)
+)
+
+// ParseGIFT reads Moodle's GIFT format. Multiple choice, multiple response (options with a positive
+// weight count as correct), true/false and missing word questions are imported, with the general feedback
+// as the explanation. Short answer, numerical, matching and essay questions have no equivalent here and are
+// skipped with a warning. The first $CATEGORY names the quiz.
+func ParseGIFT(r io.Reader) (*Parsed, error) {
+	parsed := &Parsed{}
+	scanner := bufio.NewScanner(r)
+
+	var block []string
+	start := 0
+
+	flush := func() {
+		if len(block) > 0 {
+			question, err := parseGIFTQuestion(strings.Join(block, "\n"))
+			if _, ok := err.(unsupported); ok {
+				parsed.warn(start, err.Error())
+			} else if err != nil {
+				parsed.fail(start, err.Error())
+			} else {
+				parsed.add(start, question)
+			}
+		}
+		block = nil
+	}
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "$CATEGORY:"):
+			flush()
+			if parsed.Title == "" {
+				category := strings.TrimSpace(strings.TrimPrefix(line, "$CATEGORY:"))
+				parsed.Title = category[strings.LastIndex(category, "/")+1:]
+			}
+		case line == "":
+			flush()
+		default:
+			if len(block) == 0 {
+				start = n
+			}
+			block = append(block, line)
+		}
+	}
+	flush()
+
+	return parsed, scanner.Err()
+}
+
+// WriteGIFT writes game in GIFT, with its topic as the category. Questions with several correct options
+// split the credit between them.
+func WriteGIFT(w io.Writer, game *entity.Game) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$CATEGORY: %s\n", strings.ReplaceAll(game.Topic, "/", " "))
+
+	for _, q := range game.Questions {
+		correct := 0
+		for _, o := range q.Options {
+			if o.Correct {
+				correct++
+			}
+		}
+		weight := ""
+		if correct > 1 {
+			weight = "%" + strconv.FormatFloat(100/float64(correct), 'f', -1, 64) + "%"
+		}
+
+		fmt.Fprintf(&b, "\n%s {\n", giftQuote.Replace(q.Name))
+		for _, o := range q.Options {
+			switch {
+			case o.Correct && weight != "":
+				fmt.Fprintf(&b, "\t~%s%s\n", weight, giftQuote.Replace(o.Name))
+			case o.Correct:
+				fmt.Fprintf(&b, "\t=%s\n", giftQuote.Replace(o.Name))
+			default:
+				fmt.Fprintf(&b, "\t~%s\n", giftQuote.Replace(o.Name))
+			}
+		}
+		if q.Explanation != "" {
+			fmt.Fprintf(&b, "\t####%s\n", giftQuote.Replace(q.Explanation))
+		}
+		b.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func parseGIFTQuestion(text string) (entity.CreateQuestion, error) {
+	question := entity.CreateQuestion{}
+
+	if strings.HasPrefix(text, "::") {
+		end := findUnescaped(text, "::", 2)
+		if end == -1 {
+			return question, errors.New("unterminated question title")
+		}
+		text = strings.TrimSpace(text[end+2:])
+	}
+	text = giftFormat.ReplaceAllString(text, "")
+
+	open := findUnescaped(text, "{", 0)
+	if open == -1 {
+		return question, errors.New("no answers, descriptions are not supported")
+	}
+	end := findUnescaped(text, "}", open)
+	if end == -1 {
+		return question, errors.New("missing closing brace")
+	}
+
+	question.Name = strings.TrimSpace(text[:open])
+	if after := strings.TrimSpace(text[end+1:]); after != "" {
+		question.Name += " _____ " + after
+	}
+	question.Name = giftEscape.Replace(question.Name)
+
+	answers := strings.TrimSpace(text[open+1 : end])
+	if feedback := findUnescaped(answers, "####", 0); feedback != -1 {
+		question.Explanation = giftEscape.Replace(strings.TrimSpace(answers[feedback+4:]))
+		answers = strings.TrimSpace(answers[:feedback])
+	}
+
+	switch {
+	case answers == "":
+		return question, unsupported("essay questions are not supported")
+	case strings.HasPrefix(answers, "#"):
+		return question, unsupported("numerical questions are not supported")
+	case findUnescaped(answers, "->", 0) != -1:
+		return question, unsupported("matching questions are not supported")
+	}
+
+	switch value := strings.ToUpper(stripFeedback(answers)); value {
+	case "T", "TRUE", "F", "FALSE":
+		truth := value[0] == 'T'
+		question.Options = []entity.CreateOption{{Name: "True", Correct: truth}, {Name: "False", Correct: !truth}}
+		return question, nil
+	}
+
+	var kinds []byte
+	var tokens []string
+	last := -1
+	for i := 0; i < len(answers); i++ {
+		switch answers[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if last == -1 && strings.TrimSpace(answers[:i]) != "" {
+				return question, errors.New("answers must start with = or ~")
+			}
+			if last != -1 {
+				tokens = append(tokens, answers[last+1:i])
+			}
+			kinds = append(kinds, answers[i])
+			last = i
+		}
+	}
+	if last == -1 {
+		return question, errors.New("answers must start with = or ~")
+	}
+	tokens = append(tokens, answers[last+1:])
+
+	wrong := false
+	for i, token := range tokens {
+		option := entity.CreateOption{Correct: kinds[i] == '='}
+		token = strings.TrimSpace(stripFeedback(token))
+
+		if weight := giftWeight.FindStringSubmatch(token); weight != nil {
+			option.Correct = !strings.HasPrefix(weight[1], "-") && weight[1] != "0"
+			token = strings.TrimSpace(token[len(weight[0]):])
+		}
+
+		wrong = wrong || kinds[i] == '~'
+		option.Name = giftEscape.Replace(token)
+		question.Options = append(question.Options, option)
+	}
+
+	if !wrong {
+		return question, unsupported("short answer questions are not supported")
+	}
+
+	return question, nil
+}
+
+// stripFeedback drops the per-answer feedback that follows an unescaped #.
+func stripFeedback(answer string) string {
+	if i := findUnescaped(answer, "#", 0); i != -1 {
+		return strings.TrimSpace(answer[:i])
+	}
+	return strings.TrimSpace(answer)
+}
+
+// findUnescaped returns the index of the first sub in s at or after from that is not escaped by a backslash.
+func findUnescaped(s, sub string, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGIFT(t *testing.T) {
+	t.Run("TestValid", func(t *testing.T) {
+		doc := `$CATEGORY: $course$/Science/Space
+
+// Multiple choice with feedback.
+::Planets:: Which planet is the largest? {
+	~Mars # Too small
+	=Jupiter
+	~Venus
+	~Earth
+	#### Jupiter is over 300 times as heavy as Earth.
+}
+
+The sun is a star. {T}
+
+Pick the primes {~%50%2 ~%50%3 ~%-100%4 ~%-100%6}
+
+Mount \{Everest\} is in _____ Asia. {=Nepal ~Peru}
+`
+
+		actual, err := ParseGIFT(strings.NewReader(doc))
+
+		assert.Nil(t, err)
+		assert.Empty(t, actual.Errors)
+		assert.Equal(t, "Space", actual.Title)
+		assert.Len(t, actual.Questions, 4)
+
+		planets := actual.Questions[0]
+		assert.Equal(t, "Which planet is the largest?", planets.Name)
+		assert.Equal(t, "Mars", planets.Options[0].Name)
+		assert.True(t, planets.Options[1].Correct)
+		assert.Equal(t, "Jupiter is over 300 times as heavy as Earth.", planets.Explanation)
+
+		assert.Equal(t, "True", actual.Questions[1].Options[0].Name)
+		assert.True(t, actual.Questions[1].Options[0].Correct)
+
+		primes := actual.Questions[2]
+		assert.True(t, primes.Options[0].Correct)
+		assert.True(t, primes.Options[1].Correct)
+		assert.False(t, primes.Options[2].Correct)
+
+		assert.Equal(t, "Mount {Everest} is in _____ Asia.", actual.Questions[3].Name)
+	})
+
+	t.Run("TestUnsupported", func(t *testing.T) {
+		doc := "Who wrote Hamlet? {=Shakespeare =William Shakespeare}\n\nWhat is 2+2? {#4}\n\nMatch {=a -> 1 =b -> 2}\n\nWrite an essay. {}\n\nNo answers here.\n"
+
+		actual, err := ParseGIFT(strings.NewReader(doc))
+
+		assert.Nil(t, err)
+		assert.Empty(t, actual.Questions)
+		assert.Equal(t, []int{1, 3, 5, 7}, lines(actual.Warnings))
+		assert.Equal(t, []int{9}, lines(actual.Errors))
+	})
+}
@@ -0,0 +1,104 @@
+package formats
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+const (
+	// DocumentFormat identifies JSON documents exported from here.
+	DocumentFormat = "quizzus"
+	// DocumentVersion is bumped whenever Document changes in a way older readers cannot handle.
+	DocumentVersion = 1
+)
+
+// Document is the JSON export of a game: everything needed to recreate it, without ids or owners.
+type Document struct {
+	Format  string       `json:"format"`
+	Version int          `json:"version"`
+	Game    DocumentGame `json:"game"`
+}
+
+type DocumentGame struct {
+	Topic            string             `json:"topic"`
+	RoundTime        int                `json:"round_time"`
+	Points           float64            `json:"points"`
+	Public           bool               `json:"public"`
+	ShuffleQuestions bool               `json:"shuffle_questions"`
+	ShuffleOptions   bool               `json:"shuffle_options"`
+	PoolSize         int                `json:"pool_size"`
+	Questions        []DocumentQuestion `json:"questions"`
+}
+
+type DocumentQuestion struct {
+	Name        string                `json:"name"`
+	Explanation string                `json:"explanation,omitempty"`
+	Options     []entity.CreateOption `json:"options"`
+}
+
+// WriteJSON writes game as a Document.
+func WriteJSON(w io.Writer, game *entity.Game) error {
+	doc := Document{
+		Format:  DocumentFormat,
+		Version: DocumentVersion,
+		Game: DocumentGame{
+			Topic:            game.Topic,
+			RoundTime:        game.RoundTime,
+			Points:           game.Points,
+			Public:           game.Public,
+			ShuffleQuestions: game.ShuffleQuestions,
+			ShuffleOptions:   game.ShuffleOptions,
+			PoolSize:         game.PoolSize,
+		},
+	}
+
+	for _, q := range game.Questions {
+		question := DocumentQuestion{Name: q.Name, Explanation: q.Explanation}
+		for _, o := range q.Options {
+			question.Options = append(question.Options, entity.CreateOption{Name: o.Name, Correct: o.Correct})
+		}
+		doc.Game.Questions = append(doc.Game.Questions, question)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// ParseJSON reads a Document, settings included. Errors are reported against the question's position in
+// the document rather than a line.
+func ParseJSON(r io.Reader) (*Parsed, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Format != DocumentFormat {
+		return nil, errors.New("not a " + DocumentFormat + " document")
+	}
+	if doc.Version < 1 || doc.Version > DocumentVersion {
+		return nil, errors.New("unsupported document version")
+	}
+
+	parsed := &Parsed{
+		Title: doc.Game.Topic,
+		Settings: &entity.CreateGame{
+			Topic:            doc.Game.Topic,
+			RoundTime:        doc.Game.RoundTime,
+			Points:           doc.Game.Points,
+			Public:           doc.Game.Public,
+			ShuffleQuestions: doc.Game.ShuffleQuestions,
+			ShuffleOptions:   doc.Game.ShuffleOptions,
+			PoolSize:         doc.Game.PoolSize,
+		},
+	}
+
+	for i, q := range doc.Game.Questions {
+		parsed.add(i+1, entity.CreateQuestion{Name: q.Name, Explanation: q.Explanation, Options: q.Options})
+	}
+
+	return parsed, nil
+}
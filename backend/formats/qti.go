@@ -0,0 +1,581 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/ip-05/quizzus/entity"
+)
+
+const (
+	qtiNamespace    = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiPackageNS    = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiMatchCorrect = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	qtiItemResource = "imsqti_item_xmlv2p1"
+	qtiTestResource = "imsqti_test_xmlv2p1"
+	qtiManifestFile = "imsmanifest.xml"
+	qtiTestFile     = "test.xml"
+	qtiResponse     = "RESPONSE"
+	qtiFeedback     = "FEEDBACK"
+	qtiExplanation  = "EXPLANATION"
+	qtiMaxFileSize  = 1 << 20
+	qtiBlank        = "___"
+)
+
+// interactions names the QTI interactions games have no equivalent for, as they are reported in warnings.
+var interactions = map[string]string{
+	"extendedTextInteraction":     "extended text",
+	"inlineChoiceInteraction":     "inline choice",
+	"orderInteraction":            "order",
+	"matchInteraction":            "match",
+	"associateInteraction":        "associate",
+	"gapMatchInteraction":         "gap match",
+	"hottextInteraction":          "hot text",
+	"hotspotInteraction":          "hotspot",
+	"graphicOrderInteraction":     "graphic order",
+	"graphicAssociateInteraction": "graphic associate",
+	"graphicGapMatchInteraction":  "graphic gap match",
+	"selectPointInteraction":      "select point",
+	"positionObjectInteraction":   "position object",
+	"sliderInteraction":           "slider",
+	"mediaInteraction":            "media",
+	"drawingInteraction":          "drawing",
+	"uploadInteraction":           "upload",
+	"customInteraction":           "custom",
+}
+
+// qtiBlocks are the XHTML elements that separate text in item bodies.
+var qtiBlocks = map[string]bool{"p": true, "div": true, "br": true, "li": true, "td": true, "prompt": true}
+
+type qtiManifest struct {
+	XMLName       xml.Name      `xml:"manifest"`
+	Xmlns         string        `xml:"xmlns,attr,omitempty"`
+	Identifier    string        `xml:"identifier,attr"`
+	Schema        string        `xml:"metadata>schema"`
+	SchemaVersion string        `xml:"metadata>schemaversion"`
+	Organizations string        `xml:"organizations"`
+	Resources     []qtiResource `xml:"resources>resource"`
+}
+
+type qtiResource struct {
+	Identifier   string          `xml:"identifier,attr"`
+	Type         string          `xml:"type,attr"`
+	Href         string          `xml:"href,attr"`
+	Files        []qtiHref       `xml:"file"`
+	Dependencies []qtiDependency `xml:"dependency"`
+}
+
+type qtiHref struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type qtiTest struct {
+	XMLName    xml.Name `xml:"assessmentTest"`
+	Xmlns      string   `xml:"xmlns,attr"`
+	Identifier string   `xml:"identifier,attr"`
+	Title      string   `xml:"title,attr"`
+	Part       struct {
+		Identifier     string `xml:"identifier,attr"`
+		NavigationMode string `xml:"navigationMode,attr"`
+		SubmissionMode string `xml:"submissionMode,attr"`
+		Section        struct {
+			Identifier string       `xml:"identifier,attr"`
+			Title      string       `xml:"title,attr"`
+			Visible    bool         `xml:"visible,attr"`
+			Items      []qtiItemRef `xml:"assessmentItemRef"`
+		} `xml:"assessmentSection"`
+	} `xml:"testPart"`
+}
+
+type qtiItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type qtiItem struct {
+	XMLName       xml.Name `xml:"assessmentItem"`
+	Xmlns         string   `xml:"xmlns,attr"`
+	Identifier    string   `xml:"identifier,attr"`
+	Title         string   `xml:"title,attr"`
+	Adaptive      bool     `xml:"adaptive,attr"`
+	TimeDependent bool     `xml:"timeDependent,attr"`
+	Response      struct {
+		Identifier  string   `xml:"identifier,attr"`
+		Cardinality string   `xml:"cardinality,attr"`
+		BaseType    string   `xml:"baseType,attr"`
+		Correct     []string `xml:"correctResponse>value"`
+	} `xml:"responseDeclaration"`
+	Outcomes []qtiOutcome `xml:"outcomeDeclaration"`
+	Body     struct {
+		Choice struct {
+			ResponseIdentifier string      `xml:"responseIdentifier,attr"`
+			Shuffle            bool        `xml:"shuffle,attr"`
+			MaxChoices         int         `xml:"maxChoices,attr"`
+			Prompt             string      `xml:"prompt"`
+			Choices            []qtiChoice `xml:"simpleChoice"`
+		} `xml:"choiceInteraction"`
+	} `xml:"itemBody"`
+	Processing struct {
+		Template string `xml:"template,attr"`
+	} `xml:"responseProcessing"`
+	Feedback *qtiModalFeedback `xml:"modalFeedback,omitempty"`
+}
+
+type qtiOutcome struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type qtiChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiModalFeedback struct {
+	OutcomeIdentifier string `xml:"outcomeIdentifier,attr"`
+	Identifier        string `xml:"identifier,attr"`
+	ShowHide          string `xml:"showHide,attr"`
+	Text              string `xml:",chardata"`
+}
+
+// WriteQTI writes game as an IMS content package of QTI 2.1 items, one choice interaction each, allowing
+// several choices when a question has more than one correct option. Explanations become modal feedback.
+func WriteQTI(w io.Writer, game *entity.Game) error {
+	archive := zip.NewWriter(w)
+
+	manifest := qtiManifest{
+		Xmlns:         qtiPackageNS,
+		Identifier:    "MANIFEST",
+		Schema:        "QTIv2.1 Package",
+		SchemaVersion: "1.0.0",
+	}
+	test := qtiTest{Xmlns: qtiNamespace, Identifier: "TEST", Title: game.Topic}
+	test.Part.Identifier = "PART"
+	test.Part.NavigationMode = "linear"
+	test.Part.SubmissionMode = "individual"
+	test.Part.Section.Identifier = "SECTION"
+	test.Part.Section.Title = game.Topic
+	test.Part.Section.Visible = true
+
+	testResource := qtiResource{Identifier: "TEST", Type: qtiTestResource, Href: qtiTestFile, Files: []qtiHref{{Href: qtiTestFile}}}
+
+	for i, q := range game.Questions {
+		id := fmt.Sprintf("Q%d", i+1)
+		href := "items/" + id + ".xml"
+
+		if err := writeXML(archive, href, qtiItemFor(id, q, game.ShuffleOptions)); err != nil {
+			return err
+		}
+
+		test.Part.Section.Items = append(test.Part.Section.Items, qtiItemRef{Identifier: id, Href: href})
+		testResource.Dependencies = append(testResource.Dependencies, qtiDependency{IdentifierRef: id})
+		manifest.Resources = append(manifest.Resources, qtiResource{Identifier: id, Type: qtiItemResource, Href: href, Files: []qtiHref{{Href: href}}})
+	}
+	manifest.Resources = append([]qtiResource{testResource}, manifest.Resources...)
+
+	if err := writeXML(archive, qtiTestFile, test); err != nil {
+		return err
+	}
+	if err := writeXML(archive, qtiManifestFile, manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func qtiItemFor(id string, q *entity.Question, shuffle bool) qtiItem {
+	item := qtiItem{Xmlns: qtiNamespace, Identifier: id, Title: q.Name}
+	item.Response.Identifier = qtiResponse
+	item.Response.Cardinality = "single"
+	item.Response.BaseType = "identifier"
+	item.Outcomes = []qtiOutcome{{Identifier: "SCORE", Cardinality: "single", BaseType: "float"}}
+	item.Body.Choice.ResponseIdentifier = qtiResponse
+	item.Body.Choice.Shuffle = shuffle
+	item.Body.Choice.Prompt = q.Name
+	item.Processing.Template = qtiMatchCorrect
+
+	for i, o := range q.Options {
+		choice := string(rune('A' + i))
+		item.Body.Choice.Choices = append(item.Body.Choice.Choices, qtiChoice{Identifier: choice, Text: o.Name})
+		if o.Correct {
+			item.Response.Correct = append(item.Response.Correct, choice)
+		}
+	}
+
+	item.Body.Choice.MaxChoices = 1
+	if len(item.Response.Correct) > 1 {
+		item.Response.Cardinality = "multiple"
+		item.Body.Choice.MaxChoices = 0
+	}
+
+	if q.Explanation != "" {
+		item.Outcomes = append(item.Outcomes, qtiOutcome{Identifier: qtiFeedback, Cardinality: "single", BaseType: "identifier"})
+		item.Feedback = &qtiModalFeedback{OutcomeIdentifier: qtiFeedback, Identifier: qtiExplanation, ShowHide: "show", Text: q.Explanation}
+	}
+
+	return item
+}
+
+func writeXML(archive *zip.Writer, name string, v any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(file, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	return encoder.Encode(v)
+}
+
+// ParseQTI reads an IMS content package of QTI 2.1 items. Items with a single choice or text entry
+// interaction become questions, with their modal feedback as the explanation. Other interactions have no
+// equivalent here and are skipped with a warning. Items are taken in the order of the package's test, or of
+// its manifest when it has none, and the test's title names the quiz.
+func ParseQTI(r io.Reader) (*Parsed, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("not a zip package")
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[path.Clean(file.Name)] = file
+	}
+
+	var manifest qtiManifest
+	if err := readXML(files, qtiManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+
+	parsed := &Parsed{}
+
+	var hrefs []string
+	for _, resource := range manifest.Resources {
+		if !strings.HasPrefix(resource.Type, qtiTestResource) {
+			continue
+		}
+
+		test, err := readTree(files, resource.Href)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Title = test.Attrs["title"]
+
+		for _, ref := range test.find("assessmentItemRef") {
+			hrefs = append(hrefs, path.Join(path.Dir(resource.Href), ref.Attrs["href"]))
+		}
+		break
+	}
+	if hrefs == nil {
+		for _, resource := range manifest.Resources {
+			if strings.HasPrefix(resource.Type, qtiItemResource) {
+				hrefs = append(hrefs, resource.Href)
+			}
+		}
+	}
+	if len(hrefs) == 0 {
+		return nil, errors.New("the package has no items")
+	}
+
+	for i, href := range hrefs {
+		problem := entity.ImportError{Line: i + 1, File: href}
+
+		item, err := readTree(files, href)
+		if err == nil {
+			var question entity.CreateQuestion
+			question, err = parseQTIItem(item)
+			if err == nil {
+				err = check(question)
+			}
+			if err == nil {
+				parsed.Questions = append(parsed.Questions, question)
+				continue
+			}
+		}
+
+		problem.Message = err.Error()
+		if _, ok := err.(unsupported); ok {
+			parsed.Warnings = append(parsed.Warnings, problem)
+		} else {
+			parsed.Errors = append(parsed.Errors, problem)
+		}
+	}
+
+	return parsed, nil
+}
+
+func parseQTIItem(item *xmlNode) (entity.CreateQuestion, error) {
+	question := entity.CreateQuestion{}
+
+	if item.Name != "assessmentItem" {
+		return question, errors.New("not an assessment item")
+	}
+
+	body := item.find("itemBody")
+	if len(body) == 0 {
+		return question, errors.New("the item has no body")
+	}
+
+	for _, element := range body[0].find("") {
+		if kind, ok := interactions[element.Name]; ok {
+			return question, unsupported(kind + " interactions are not supported")
+		}
+	}
+	choices := body[0].find("choiceInteraction")
+	entries := body[0].find("textEntryInteraction")
+	switch {
+	case len(choices)+len(entries) == 0:
+		return question, unsupported("items without a choice or text entry interaction are not supported")
+	case len(choices)+len(entries) > 1:
+		return question, unsupported("items with several interactions are not supported")
+	case len(entries) == 1:
+		return parseQTITextEntry(item, body[0], entries[0])
+	}
+	choice := choices[0]
+
+	correct := map[string]bool{}
+	for _, declaration := range item.find("responseDeclaration") {
+		if declaration.Attrs["identifier"] != choice.Attrs["responseIdentifier"] {
+			continue
+		}
+		for _, response := range declaration.find("correctResponse") {
+			for _, value := range response.find("value") {
+				correct[value.text()] = true
+			}
+		}
+		// Items scored by a mapping instead mark the choices worth points.
+		for _, entry := range declaration.find("mapEntry") {
+			if qtiScores(entry.Attrs["mappedValue"]) {
+				correct[entry.Attrs["mapKey"]] = true
+			}
+		}
+	}
+
+	if prompt := choice.find("prompt"); len(prompt) > 0 {
+		question.Name = prompt[0].text()
+	}
+	if text := strings.Join(strings.Fields(body[0].textWithout("choiceInteraction")), " "); text != "" {
+		if question.Name == "" {
+			question.Name = text
+		} else {
+			question.Name = text + " " + question.Name
+		}
+	}
+	if question.Name == "" {
+		question.Name = item.Attrs["title"]
+	}
+
+	for _, option := range choice.find("simpleChoice") {
+		question.Options = append(question.Options, entity.CreateOption{
+			Name:    option.text(),
+			Correct: correct[option.Attrs["identifier"]],
+		})
+	}
+
+	if feedback := item.find("modalFeedback"); len(feedback) > 0 {
+		question.Explanation = feedback[0].text()
+	}
+
+	return question, nil
+}
+
+// parseQTITextEntry makes a choice question of a text entry item with a single answer, as games only have
+// choice questions. The answers its mapping scores at nothing become the wrong options, so items without
+// any are skipped with a warning. The entry shows as a blank in the question.
+func parseQTITextEntry(item, body, entry *xmlNode) (entity.CreateQuestion, error) {
+	question := entity.CreateQuestion{}
+
+	var answers, wrong []string
+	for _, declaration := range item.find("responseDeclaration") {
+		if declaration.Attrs["identifier"] != entry.Attrs["responseIdentifier"] {
+			continue
+		}
+		for _, response := range declaration.find("correctResponse") {
+			for _, value := range response.find("value") {
+				answers = append(answers, value.text())
+			}
+		}
+		for _, mapped := range declaration.find("mapEntry") {
+			if !qtiScores(mapped.Attrs["mappedValue"]) {
+				wrong = append(wrong, mapped.Attrs["mapKey"])
+			}
+		}
+	}
+
+	switch {
+	case len(answers) == 0:
+		return question, errors.New("the text entry has no correct answer")
+	case len(answers) > 1:
+		return question, unsupported("text entries with several answers are not supported")
+	case len(wrong) == 0:
+		return question, unsupported("text entries without wrong answers in their mapping are not supported")
+	}
+
+	entry.Text = qtiBlank
+	question.Name = strings.Join(strings.Fields(body.text()), " ")
+	if question.Name == "" {
+		question.Name = item.Attrs["title"]
+	}
+
+	count := 2
+	if len(wrong) >= 3 {
+		count = 4
+	}
+	question.Options = append(question.Options, entity.CreateOption{Name: answers[0], Correct: true})
+	for _, answer := range wrong[:count-1] {
+		question.Options = append(question.Options, entity.CreateOption{Name: answer})
+	}
+
+	if feedback := item.find("modalFeedback"); len(feedback) > 0 {
+		question.Explanation = feedback[0].text()
+	}
+
+	return question, nil
+}
+
+// qtiScores reports whether a mapped value gives any points.
+func qtiScores(value string) bool {
+	return !strings.HasPrefix(value, "-") && strings.Trim(value, "0.") != ""
+}
+
+func readXML(files map[string]*zip.File, name string, v any) error {
+	data, err := readFile(files, name)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func readFile(files map[string]*zip.File, name string) ([]byte, error) {
+	file, ok := files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing from the package", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, qtiMaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > qtiMaxFileSize {
+		return nil, fmt.Errorf("%s is too large", name)
+	}
+	return data, nil
+}
+
+// xmlNode is an element of an XML document, kept loosely so items can nest interactions in any markup.
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Children []*xmlNode
+	Text     string
+}
+
+func readTree(files map[string]*zip.File, name string) (*xmlNode, error) {
+	data, err := readFile(files, name)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: token.Name.Local, Attrs: map[string]string{}}
+			for _, attr := range token.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.Children = append(parent.Children, &xmlNode{Text: string(token)})
+		}
+	}
+
+	for _, child := range root.Children {
+		if child.Name != "" {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no root element", name)
+}
+
+// find returns the elements called name below n, in document order, or all of them for an empty name.
+func (n *xmlNode) find(name string) []*xmlNode {
+	var found []*xmlNode
+	for _, child := range n.Children {
+		if child.Name != "" && (name == "" || child.Name == name) {
+			found = append(found, child)
+		}
+		found = append(found, child.find(name)...)
+	}
+	return found
+}
+
+// text is the text below n, trimmed.
+func (n *xmlNode) text() string {
+	return strings.TrimSpace(n.textWithout(""))
+}
+
+// textWithout is the text below n, leaving out elements called skip. Blocks such as paragraphs are kept
+// apart by a space.
+func (n *xmlNode) textWithout(skip string) string {
+	var b strings.Builder
+	var collect func(*xmlNode)
+	collect = func(node *xmlNode) {
+		b.WriteString(node.Text)
+		for _, child := range node.Children {
+			if child.Name != "" && child.Name == skip {
+				continue
+			}
+			collect(child)
+			if qtiBlocks[child.Name] {
+				b.WriteString(" ")
+			}
+		}
+	}
+	collect(n)
+
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const qtiTestManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest xmlns="http://www.imsglobal.org/xsd/imscp_v1p1" identifier="M1">
+  <resources>
+    <resource identifier="choice" type="imsqti_item_xmlv2p1" href="choice.xml"/>
+    <resource identifier="multiple" type="imsqti_item_xmlv2p1" href="multiple.xml"/>
+    <resource identifier="text" type="imsqti_item_xmlv2p1" href="text.xml"/>
+    <resource identifier="open" type="imsqti_item_xmlv2p1" href="open.xml"/>
+    <resource identifier="order" type="imsqti_item_xmlv2p1" href="order.xml"/>
+    <resource identifier="broken" type="imsqti_item_xmlv2p1" href="broken.xml"/>
+  </resources>
+</manifest>`
+
+const qtiTestChoice = `<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="choice" title="Tomato">
+  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+    <correctResponse><value>red</value></correctResponse>
+  </responseDeclaration>
+  <itemBody>
+    <p>Look at the <b>tomato</b>.</p>
+    <div>
+      <choiceInteraction responseIdentifier="RESPONSE" maxChoices="1">
+        <prompt>What color is it?</prompt>
+        <simpleChoice identifier="red">Red</simpleChoice>
+        <simpleChoice identifier="green">Green</simpleChoice>
+      </choiceInteraction>
+    </div>
+  </itemBody>
+  <modalFeedback outcomeIdentifier="FEEDBACK" identifier="EXPLANATION" showHide="show">Ripe ones are red.</modalFeedback>
+</assessmentItem>`
+
+const qtiTestMultiple = `<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="multiple" title="Primes">
+  <responseDeclaration identifier="RESPONSE" cardinality="multiple" baseType="identifier">
+    <mapping defaultValue="0">
+      <mapEntry mapKey="a" mappedValue="0.5"/>
+      <mapEntry mapKey="b" mappedValue="0.5"/>
+      <mapEntry mapKey="c" mappedValue="-1"/>
+      <mapEntry mapKey="d" mappedValue="0"/>
+    </mapping>
+  </responseDeclaration>
+  <itemBody>
+    <choiceInteraction responseIdentifier="RESPONSE" maxChoices="0">
+      <prompt>Pick the primes</prompt>
+      <simpleChoice identifier="a">2</simpleChoice>
+      <simpleChoice identifier="b">3</simpleChoice>
+      <simpleChoice identifier="c">4</simpleChoice>
+      <simpleChoice identifier="d">6</simpleChoice>
+    </choiceInteraction>
+  </itemBody>
+</assessmentItem>`
+
+const qtiTestText = `<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="text" title="Capital">
+  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string">
+    <correctResponse><value>Paris</value></correctResponse>
+    <mapping defaultValue="0">
+      <mapEntry mapKey="Paris" mappedValue="1"/>
+      <mapEntry mapKey="Lyon" mappedValue="0"/>
+      <mapEntry mapKey="Nice" mappedValue="0"/>
+    </mapping>
+  </responseDeclaration>
+  <itemBody><p>The capital of France is <textEntryInteraction responseIdentifier="RESPONSE"/>.</p></itemBody>
+</assessmentItem>`
+
+const qtiTestOpen = `<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="open" title="Capital">
+  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string">
+    <correctResponse><value>Rome</value></correctResponse>
+  </responseDeclaration>
+  <itemBody><p>The capital of Italy is <textEntryInteraction responseIdentifier="RESPONSE"/>.</p></itemBody>
+</assessmentItem>`
+
+const qtiTestOrder = `<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="order" title="Order">
+  <itemBody><orderInteraction responseIdentifier="RESPONSE"/></itemBody>
+</assessmentItem>`
+
+func TestParseQTI(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"imsmanifest.xml": qtiTestManifest,
+		"choice.xml":      qtiTestChoice,
+		"multiple.xml":    qtiTestMultiple,
+		"text.xml":        qtiTestText,
+		"open.xml":        qtiTestOpen,
+		"order.xml":       qtiTestOrder,
+		"broken.xml":      "<assessmentItem>",
+	} {
+		file, _ := archive.Create(name)
+		file.Write([]byte(content))
+	}
+	archive.Close()
+
+	actual, err := ParseQTI(&buf)
+
+	assert.Nil(t, err)
+	assert.Len(t, actual.Questions, 3)
+
+	tomato := actual.Questions[0]
+	assert.Equal(t, "Look at the tomato. What color is it?", tomato.Name)
+	assert.Equal(t, "Ripe ones are red.", tomato.Explanation)
+	assert.True(t, tomato.Options[0].Correct)
+	assert.False(t, tomato.Options[1].Correct)
+
+	primes := actual.Questions[1]
+	assert.True(t, primes.Options[0].Correct)
+	assert.True(t, primes.Options[1].Correct)
+	assert.False(t, primes.Options[2].Correct)
+	assert.False(t, primes.Options[3].Correct)
+
+	capital := actual.Questions[2]
+	assert.Equal(t, "The capital of France is ___.", capital.Name)
+	assert.Len(t, capital.Options, 2)
+	assert.Equal(t, "Paris", capital.Options[0].Name)
+	assert.True(t, capital.Options[0].Correct)
+	assert.False(t, capital.Options[1].Correct)
+
+	assert.Len(t, actual.Warnings, 2)
+	assert.Equal(t, "open.xml", actual.Warnings[0].File)
+	assert.Equal(t, "text entries without wrong answers in their mapping are not supported", actual.Warnings[0].Message)
+	assert.Equal(t, "order.xml", actual.Warnings[1].File)
+	assert.Equal(t, "order interactions are not supported", actual.Warnings[1].Message)
+
+	assert.Len(t, actual.Errors, 1)
+	assert.Equal(t, "broken.xml", actual.Errors[0].File)
+
+	t.Run("TestNotZip", func(t *testing.T) {
+		_, err := ParseQTI(bytes.NewBufferString("question,option_a"))
+		assert.NotNil(t, err)
+	})
+}
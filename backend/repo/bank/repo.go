@@ -0,0 +1,91 @@
+package bank
+
+import (
+	"github.com/ip-05/quizzus/entity"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{
+		DB: db,
+	}
+}
+
+func (r Repository) GetQuestion(ID int) *entity.BankQuestion {
+	var question entity.BankQuestion
+	r.DB.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id = ?", ID).First(&question)
+	return &question
+}
+
+func (r Repository) SearchQuestions(ownerID uint, filter entity.BankFilter) []entity.BankQuestion {
+	var questions []entity.BankQuestion
+
+	query := r.DB.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("owner_id = ?", ownerID)
+	if filter.Cursor != 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Query != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Query+"%")
+	}
+	if filter.Tag != "" {
+		// Tags are normalized without quotes or wildcards, so the quoted tag only matches whole tags.
+		query = query.Where("tags LIKE ?", `%"`+filter.Tag+`"%`)
+	}
+	if filter.Difficulty != "" {
+		query = query.Where("difficulty = ?", filter.Difficulty)
+	}
+
+	query.Order("id DESC").Limit(filter.Limit).Find(&questions)
+	return questions
+}
+
+func (r Repository) CreateQuestion(e *entity.BankQuestion) *entity.BankQuestion {
+	r.DB.Session(&gorm.Session{FullSaveAssociations: true}).Create(&e)
+	return e
+}
+
+// UpdateQuestion saves e and replaces its options with e.Options.
+func (r Repository) UpdateQuestion(e *entity.BankQuestion) *entity.BankQuestion {
+	r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bank_question_id = ?", e.ID).Delete(&entity.BankOption{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&e).Error
+	})
+	return e
+}
+
+// DeleteQuestion deletes e and its options. Game questions copied from it are kept but no longer linked.
+func (r Repository) DeleteQuestion(e *entity.BankQuestion) {
+	r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Question{}).Where("bank_question_id = ?", e.ID).Update("bank_question_id", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bank_question_id = ?", e.ID).Delete(&entity.BankOption{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&entity.BankQuestion{}, e.ID).Error
+	})
+}
+
+// GetUsage returns the games of e's owner that use a copy of e.
+func (r Repository) GetUsage(e *entity.BankQuestion) []uint {
+	var gameIDs []uint
+	r.DB.Model(&entity.Question{}).
+		Distinct("questions.game_id").
+		Joins("INNER JOIN games ON games.id = questions.game_id").
+		Where("questions.bank_question_id = ? and games.owner = ?", e.ID, e.OwnerID).
+		Order("questions.game_id").
+		Pluck("questions.game_id", &gameIDs)
+	return gameIDs
+}
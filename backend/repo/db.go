@@ -28,7 +28,14 @@ func New(cfg *config.Config) *gorm.DB {
 		&entity.User{},
 		&entity.FavoriteGame{},
 		&entity.GameSession{},
-		&entity.GameSession{},
+		&entity.GameInstance{},
+		&entity.Answer{},
+		&entity.RatingChange{},
+		&entity.Achievement{},
+		&entity.BankQuestion{},
+		&entity.BankOption{},
+		&entity.GameRevision{},
+		&entity.Collaborator{},
 	)
 	if err != nil {
 		log.Print("FAIL_MIGRATIONS")
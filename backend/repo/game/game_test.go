@@ -87,3 +87,64 @@ func TestRepo_UpdateGame(t *testing.T) {
 	updatedGame := repo.UpdateGame(int(game.ID), game.InviteCode, game)
 	assert.Equal(t, updatedGame.Topic, "Updated topic")
 }
+
+func TestRepo_UpdateGameSettingsOff(t *testing.T) {
+	db, cleanup := SetupIntegration(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+
+	body := testGameBody
+	body.ShuffleQuestions = true
+	body.ShuffleOptions = true
+	body.PoolSize = 1
+	newGame, err := entity.NewGame(body, uint(1))
+	assert.Nil(t, err)
+
+	game := repo.CreateGame(newGame)
+	assert.Greater(t, game.ID, uint(0))
+
+	game.ShuffleQuestions = false
+	game.ShuffleOptions = false
+	game.PoolSize = 0
+	repo.UpdateGame(int(game.ID), game.InviteCode, game)
+
+	gotGame := repo.GetGame(int(game.ID), game.InviteCode)
+	assert.False(t, gotGame.ShuffleQuestions)
+	assert.False(t, gotGame.ShuffleOptions)
+	assert.Equal(t, 0, gotGame.PoolSize)
+	assert.Equal(t, entity.GamePublished, gotGame.Status)
+}
+
+func TestRepo_RollbackSettingsOff(t *testing.T) {
+	db, cleanup := SetupIntegration(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+
+	newGame, err := entity.NewGame(testGameBody, uint(1))
+	assert.Nil(t, err)
+
+	game := repo.CreateGame(newGame)
+	repo.CreateRevision(entity.NewRevision(game, uint(1)))
+
+	game.Public = true
+	game.ShuffleQuestions = true
+	game.PoolSize = 1
+	game.Revision = 2
+	repo.UpdateGame(int(game.ID), game.InviteCode, game)
+
+	revision := repo.GetRevision(game.ID, 1)
+	current := repo.GetGame(int(game.ID), game.InviteCode)
+	assert.True(t, current.Public)
+
+	revision.Content.Restore(current)
+	current.Revision = 3
+	repo.UpdateGame(int(current.ID), current.InviteCode, current)
+
+	gotGame := repo.GetGame(int(game.ID), game.InviteCode)
+	assert.False(t, gotGame.Public)
+	assert.False(t, gotGame.ShuffleQuestions)
+	assert.Equal(t, 0, gotGame.PoolSize)
+	assert.Equal(t, 3, gotGame.Revision)
+}
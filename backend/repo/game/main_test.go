@@ -32,6 +32,8 @@ func SetupIntegration(t *testing.T) (*gorm.DB, func() error) {
 		&entity.Option{},
 		&entity.Question{},
 		&entity.Game{},
+		&entity.Collaborator{},
+		&entity.GameRevision{},
 	)
 	if err != nil {
 		return nil, nil
@@ -58,6 +60,16 @@ func SetupIntegration(t *testing.T) (*gorm.DB, func() error) {
 			return res.Error
 		}
 
+		res = db.Exec("TRUNCATE TABLE collaborators;")
+		if res.Error != nil {
+			return res.Error
+		}
+
+		res = db.Exec("TRUNCATE TABLE game_revisions;")
+		if res.Error != nil {
+			return res.Error
+		}
+
 		res = db.Exec("SET session_replication_role = 'origin';")
 		if res.Error != nil {
 			return res.Error
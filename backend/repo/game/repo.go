@@ -18,7 +18,7 @@ func NewRepository(db *gorm.DB) *Repository {
 
 func (r Repository) GetGame(ID int, code string) *entity.Game {
 	var game entity.Game
-	r.DB.Preload("Questions.Options").Where("invite_code = ? or id = ?", code, ID).First(&game)
+	r.DB.Preload("Questions.Options").Preload("Collaborators").Where("invite_code = ? or id = ?", code, ID).First(&game)
 	return &game
 }
 
@@ -28,19 +28,20 @@ func (r Repository) GetFavoriteGame(user int) *[]entity.Game {
 		Preload("Questions.Options").
 		Select("games.*").
 		Joins("INNER JOIN favorite_games ON favorite_games.game_id = games.id").
-		Where("favorite_games.user_id = ?", user).Find(&games)
+		Where("favorite_games.user_id = ? and (games.status <> ? or games.owner = ?)", user, entity.GameDraft, user).
+		Find(&games)
 	return games
 }
 
 func (r Repository) GetGameByOwner(ID int, hidePrivate bool, limit int) *[]entity.Game {
 	var games *[]entity.Game
 
-	hide := ""
+	query := r.DB.Preload("Questions.Options").Where("owner = ?", ID)
 	if hidePrivate {
-		hide = " and public = true"
+		query = query.Where("public = true and status <> ?", entity.GameDraft)
 	}
 
-	r.DB.Preload("Questions.Options").Where("owner = ?"+hide, ID).Limit(limit).Find(&games)
+	query.Limit(limit).Find(&games)
 	return games
 }
 
@@ -49,11 +50,27 @@ func (r Repository) CreateGame(e *entity.Game) *entity.Game {
 	return e
 }
 
+// UpdateGame saves the game's settings and questions. Every column is selected so that settings turned off
+// are saved as well, except those only changed by their own updates.
 func (r Repository) UpdateGame(ID int, code string, e *entity.Game) *entity.Game {
-	r.DB.Session(&gorm.Session{FullSaveAssociations: true}).Where("invite_code = ? or id = ?", code, ID).Updates(&e)
+	r.DB.Session(&gorm.Session{FullSaveAssociations: true}).
+		Where("invite_code = ? or id = ?", code, ID).
+		Select("*").
+		Omit("id", "invite_code", "owner", "forked_from", "forked_from_owner", "status", "created_at").
+		Updates(&e)
 	return e
 }
 
+// ClaimRevision moves the game from revision current to next, and reports false if it was no longer at current.
+func (r Repository) ClaimRevision(ID uint, current, next int) bool {
+	result := r.DB.Model(&entity.Game{}).Where("id = ? and revision = ?", ID, current).Update("revision", next)
+	return result.RowsAffected == 1
+}
+
+func (r Repository) SetStatus(ID uint, status string) {
+	r.DB.Model(&entity.Game{}).Where("id = ?", ID).Update("status", status)
+}
+
 func (r Repository) DeleteGame(e *entity.Game) {
 	for _, v := range e.Questions {
 		r.DB.Select(clause.Associations).Unscoped().Delete(&v)
@@ -66,6 +83,41 @@ func (r Repository) DeleteQuestion(ID int) {
 	r.DB.Exec("DELETE FROM options WHERE question_id = ?", ID)
 }
 
+// GetSessionStats sums up the finished sessions of a game, leaving out whoever hosted each run.
+func (r Repository) GetSessionStats(ID uint) entity.SessionStats {
+	var stats entity.SessionStats
+	r.DB.Model(&entity.GameSession{}).
+		Select("count(distinct game_sessions.instance_id) as plays, coalesce(avg(game_sessions.points), 0) as average_score").
+		Joins("INNER JOIN games ON games.id = game_sessions.game_id").
+		Joins("LEFT JOIN game_instances ON game_instances.id = game_sessions.instance_id").
+		Where("game_sessions.game_id = ? and game_sessions.user_id <> coalesce(game_instances.host_id, games.owner)", ID).
+		Where("game_sessions.ended_at >= game_sessions.started_at").
+		Scan(&stats)
+	return stats
+}
+
+func (r Repository) GetQuestionStats(ID uint) []entity.QuestionStats {
+	var stats []entity.QuestionStats
+	r.DB.Model(&entity.Answer{}).
+		Select("question_id, count(*) as answers, "+
+			"sum(case when correct then 1 else 0 end) as correct, "+
+			"coalesce(avg(case when option_id <> 0 then response_time end), 0) as average_response_time").
+		Where("game_id = ?", ID).
+		Group("question_id").
+		Scan(&stats)
+	return stats
+}
+
+func (r Repository) GetWrongOptionStats(ID uint) []entity.OptionStats {
+	var stats []entity.OptionStats
+	r.DB.Model(&entity.Answer{}).
+		Select("question_id, option_id, count(*) as picks").
+		Where("game_id = ? and correct = false and option_id <> 0", ID).
+		Group("question_id, option_id").
+		Scan(&stats)
+	return stats
+}
+
 func (r Repository) ToggleFavoriteGame(e *entity.FavoriteGame) bool {
 	favorite := entity.FavoriteGame{}
 	r.DB.Where("favorite_games.game_id = ? and favorite_games.user_id = ?", e.GameID, e.UserID).First(&favorite)
@@ -78,3 +130,38 @@ func (r Repository) ToggleFavoriteGame(e *entity.FavoriteGame) bool {
 	r.DB.Where("favorite_games.game_id = ? and favorite_games.user_id = ?", e.GameID, e.UserID).Delete(&favorite)
 	return false
 }
+
+func (r Repository) CreateRevision(e *entity.GameRevision) *entity.GameRevision {
+	r.DB.Create(&e)
+	return e
+}
+
+func (r Repository) GetRevision(gameID uint, number int) *entity.GameRevision {
+	var revision entity.GameRevision
+	r.DB.Where("game_id = ? and number = ?", gameID, number).First(&revision)
+	return &revision
+}
+
+func (r Repository) GetRevisions(gameID uint) []entity.GameRevision {
+	var revisions []entity.GameRevision
+	r.DB.Where("game_id = ?", gameID).Order("number").Find(&revisions)
+	return revisions
+}
+
+func (r Repository) SaveCollaborator(e *entity.Collaborator) *entity.Collaborator {
+	r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "game_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&e)
+	return e
+}
+
+func (r Repository) DeleteCollaborator(gameID, userID uint) {
+	r.DB.Where("game_id = ? and user_id = ?", gameID, userID).Delete(&entity.Collaborator{})
+}
+
+func (r Repository) UserExists(ID uint) bool {
+	var count int64
+	r.DB.Model(&entity.User{}).Where("id = ?", ID).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,85 @@
+package leaderboard
+
+import (
+	"time"
+
+	"github.com/ip-05/quizzus/entity"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{
+		DB: db,
+	}
+}
+
+// GetStandings aggregates finished sessions started since from. Hosts are left out, falling back to the
+// game owner for sessions played before instances recorded their host, and a win is a game where the
+// player had the top score, provided anyone scored at all. Players who keep their stats private still count
+// towards the top scores but are not listed.
+func (r Repository) GetStandings(query entity.LeaderboardQuery, from time.Time) []entity.PlayerStanding {
+	var standings []entity.PlayerStanding
+
+	scope := "games.public = true"
+	args := []any{from}
+	if query.GameID != 0 {
+		scope = "game_sessions.game_id = ?"
+		args = append(args, query.GameID)
+	}
+
+	order := "points DESC, wins DESC"
+	having := ""
+	switch query.Sort {
+	case entity.SortBest:
+		order = "best DESC, points DESC"
+	case entity.SortWinRate:
+		order = "sum(case when p.points = t.top and t.top > 0 then 1 else 0 end)::float / count(*) DESC, points DESC"
+		having = "HAVING count(*) >= ?"
+		args = append(args, entity.MinRankedGames)
+	}
+	args = append(args, query.Limit)
+
+	r.DB.Raw(`
+		WITH players AS (
+			SELECT game_sessions.instance_id, game_sessions.user_id, game_sessions.points
+			FROM game_sessions
+			INNER JOIN games ON games.id = game_sessions.game_id
+			LEFT JOIN game_instances ON game_instances.id = game_sessions.instance_id
+			WHERE game_sessions.user_id <> coalesce(game_instances.host_id, games.owner)
+				AND game_sessions.ended_at >= game_sessions.started_at
+				AND game_sessions.started_at >= ?
+				AND `+scope+`
+		), tops AS (
+			SELECT instance_id, max(points) AS top FROM players GROUP BY instance_id
+		)
+		SELECT p.user_id, users.name, users.picture,
+			sum(p.points) AS points, max(p.points) AS best, count(*) AS games,
+			sum(case when p.points = t.top and t.top > 0 then 1 else 0 end) AS wins
+		FROM players p
+		INNER JOIN tops t ON t.instance_id = p.instance_id
+		INNER JOIN users ON users.id = p.user_id
+		WHERE users.private_stats = false
+		GROUP BY p.user_id, users.name, users.picture
+		`+having+`
+		ORDER BY `+order+`
+		LIMIT ?`, args...).Scan(&standings)
+
+	return standings
+}
+
+// GetRatingStandings ranks the players with enough rated games by rating, leaving out those who keep their
+// stats private.
+func (r Repository) GetRatingStandings(limit int) []entity.RatingStanding {
+	var standings []entity.RatingStanding
+	r.DB.Model(&entity.User{}).
+		Select("id as user_id, name, picture, rating, rated_games").
+		Where("rated_games >= ? AND private_stats = false", entity.MinRatedGames).
+		Order("rating DESC").
+		Limit(limit).
+		Scan(&standings)
+	return standings
+}
@@ -15,40 +15,65 @@ func NewRepository(db *gorm.DB) *Repository {
 	}
 }
 
-func (r Repository) GetSessions(userID, limit int) *[]entity.GameSession {
+func (r Repository) GetSessions(userID int, filter entity.SessionFilter) []entity.GameSession {
 	var sessions []entity.GameSession
 
-	r.DB.Preload("Game").Select("*").Where("user_id = ?", userID).Limit(limit).Order("id DESC").Find(&sessions)
-
-	for i := 0; i < len(sessions); i++ {
-		var leaderboard *[]entity.Leaderboard
-		r.DB.Model(&entity.GameSession{}).Select("users.id, users.name, points").
-			Joins("INNER JOIN users ON users.id = user_id").
-			Where("game_sessions.instance_id = ?", sessions[0].InstanceID).
-			Order("points DESC").
-			Scan(&leaderboard)
-
-		sessions[i].Leaderboard = leaderboard
+	query := r.DB.Preload("Game").Where("user_id = ?", userID)
+	if filter.Cursor != 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.GameID != 0 {
+		query = query.Where("game_id = ?", filter.GameID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("started_at < ?", filter.To)
+	}
+	if filter.PublicOnly {
+		query = query.Where("game_id IN (?)", r.DB.Model(&entity.Game{}).Select("id").Where("public = ?", true))
 	}
 
-	return &sessions
+	query.Order("id DESC").Limit(filter.Limit).Find(&sessions)
+	return sessions
 }
 
 func (r Repository) GetSession(ID, userID int) *entity.GameSession {
 	var session entity.GameSession
+	r.DB.Preload("Game").Where("user_id = ? and id = ?", userID, ID).First(&session)
+	return &session
+}
 
-	r.DB.Preload("Game").Select("*").Where("user_id = ? and id = ?", userID, ID).First(&session)
+func (r Repository) GetLeaderboards(instIDs []uint) map[uint][]entity.Leaderboard {
+	var rows []struct {
+		InstanceID uint
+		entity.Leaderboard
+	}
 
-	var leaderboard *[]entity.Leaderboard
-	r.DB.Model(&entity.GameSession{}).Select("users.id, users.name, points").
-		Joins("INNER JOIN users ON users.id = user_id").
-		Where("game_sessions.instance_id = ?", session.InstanceID).
-		Order("points DESC").
-		Scan(&leaderboard)
+	r.DB.Model(&entity.GameSession{}).
+		Select("game_sessions.instance_id, users.id as user_id, users.name, game_sessions.points").
+		Joins("INNER JOIN users ON users.id = game_sessions.user_id").
+		Where("game_sessions.instance_id in ?", instIDs).
+		Order("game_sessions.points DESC").
+		Scan(&rows)
 
-	session.Leaderboard = leaderboard
+	leaderboards := make(map[uint][]entity.Leaderboard)
+	for _, row := range rows {
+		leaderboards[row.InstanceID] = append(leaderboards[row.InstanceID], row.Leaderboard)
+	}
+	return leaderboards
+}
 
-	return &session
+func (r Repository) GetHosts(instIDs []uint) map[uint]uint {
+	var instances []entity.GameInstance
+	r.DB.Select("id, host_id").Where("id in ?", instIDs).Find(&instances)
+
+	hosts := make(map[uint]uint)
+	for _, instance := range instances {
+		hosts[instance.ID] = instance.HostID
+	}
+	return hosts
 }
 
 func (r Repository) CreateSession(e *entity.GameSession) *entity.GameSession {
@@ -57,6 +82,172 @@ func (r Repository) CreateSession(e *entity.GameSession) *entity.GameSession {
 }
 
 func (r Repository) EndSession(e *entity.GameSession) *entity.GameSession {
-	r.DB.Where("user_id = ? and game_id = ?", e.UserID, e.GameID).Updates(&e)
+	r.DB.Where("user_id = ? and instance_id = ?", e.UserID, e.InstanceID).Updates(&e)
+	return e
+}
+
+func (r Repository) CreateInstance(e *entity.GameInstance) *entity.GameInstance {
+	r.DB.Create(&e)
 	return e
 }
+
+func (r Repository) EndInstance(e *entity.GameInstance) *entity.GameInstance {
+	r.DB.Where("id = ?", e.ID).Updates(&e)
+	return e
+}
+
+func (r Repository) GetInstance(ID int) *entity.GameInstance {
+	var instance entity.GameInstance
+	r.DB.Preload("Game.Questions.Options").Where("id = ?", ID).First(&instance)
+	return &instance
+}
+
+func (r Repository) GetRevision(gameID uint, number int) *entity.GameRevision {
+	var revision entity.GameRevision
+	r.DB.Where("game_id = ? and number = ?", gameID, number).First(&revision)
+	return &revision
+}
+
+func (r Repository) GetLeaderboard(instID uint) []entity.Leaderboard {
+	return r.GetLeaderboards([]uint{instID})[instID]
+}
+
+func (r Repository) GetInstanceAnswers(instID uint) []entity.Answer {
+	var answers []entity.Answer
+	r.DB.Where("instance_id = ?", instID).Find(&answers)
+	return answers
+}
+
+func (r Repository) CreateAnswers(e []*entity.Answer) {
+	if len(e) == 0 {
+		return
+	}
+	r.DB.Create(&e)
+}
+
+// GetProgress counts the finished games, wins and hosted games of each user. A win is a game where the
+// user had the top score among the players, provided anyone scored at all.
+func (r Repository) GetProgress(userIDs []uint) map[uint]entity.Progress {
+	var played []struct {
+		UserID uint
+		Games  int64
+		Wins   int64
+	}
+
+	r.DB.Raw(`
+		WITH players AS (
+			SELECT game_sessions.instance_id, game_sessions.user_id, game_sessions.points
+			FROM game_sessions
+			INNER JOIN games ON games.id = game_sessions.game_id
+			LEFT JOIN game_instances ON game_instances.id = game_sessions.instance_id
+			WHERE game_sessions.user_id <> coalesce(game_instances.host_id, games.owner)
+				AND game_sessions.ended_at >= game_sessions.started_at
+		), tops AS (
+			SELECT instance_id, max(points) AS top FROM players
+			WHERE instance_id IN (SELECT instance_id FROM players WHERE user_id IN ?)
+			GROUP BY instance_id
+		)
+		SELECT p.user_id, count(*) AS games,
+			sum(case when p.points = t.top and t.top > 0 then 1 else 0 end) AS wins
+		FROM players p
+		INNER JOIN tops t ON t.instance_id = p.instance_id
+		WHERE p.user_id IN ?
+		GROUP BY p.user_id`, userIDs, userIDs).Scan(&played)
+
+	var hosted []struct {
+		HostID uint
+		Hosted int64
+	}
+
+	r.DB.Model(&entity.GameInstance{}).
+		Select("host_id, count(*) as hosted").
+		Where("host_id in ? and ended_at >= started_at", userIDs).
+		Group("host_id").
+		Scan(&hosted)
+
+	progress := make(map[uint]entity.Progress, len(userIDs))
+	for _, row := range played {
+		p := progress[row.UserID]
+		p.Games, p.Wins = row.Games, row.Wins
+		progress[row.UserID] = p
+	}
+	for _, row := range hosted {
+		p := progress[row.HostID]
+		p.Hosted = row.Hosted
+		progress[row.HostID] = p
+	}
+	return progress
+}
+
+// GetRecentAnswers returns up to limit of each user's latest answers, newest first.
+func (r Repository) GetRecentAnswers(userIDs []uint, limit int) map[uint][]entity.Answer {
+	var answers []entity.Answer
+
+	r.DB.Raw(`
+		SELECT * FROM (
+			SELECT answers.*, row_number() OVER (PARTITION BY user_id ORDER BY id DESC) AS n
+			FROM answers WHERE user_id IN ?
+		) recent
+		WHERE n <= ?
+		ORDER BY id DESC`, userIDs, limit).Scan(&answers)
+
+	recent := make(map[uint][]entity.Answer)
+	for _, answer := range answers {
+		recent[answer.UserID] = append(recent[answer.UserID], answer)
+	}
+	return recent
+}
+
+func (r Repository) GetAchievements(userIDs []uint) map[uint][]entity.Achievement {
+	var achievements []entity.Achievement
+	r.DB.Where("user_id in ?", userIDs).Find(&achievements)
+
+	unlocked := make(map[uint][]entity.Achievement)
+	for _, achievement := range achievements {
+		unlocked[achievement.UserID] = append(unlocked[achievement.UserID], achievement)
+	}
+	return unlocked
+}
+
+func (r Repository) CreateAchievements(e []*entity.Achievement) {
+	if len(e) == 0 {
+		return
+	}
+	r.DB.Create(&e)
+}
+
+func (r Repository) GetAccuracy(userID uint) (int64, int64) {
+	var row struct {
+		Answers int64
+		Correct int64
+	}
+
+	r.DB.Model(&entity.Answer{}).
+		Select("count(*) as answers, coalesce(sum(case when correct then 1 else 0 end), 0) as correct").
+		Where("user_id = ?", userID).
+		Scan(&row)
+
+	return row.Answers, row.Correct
+}
+
+// GetFavouriteTopics returns the topics of the games the user has played most, leaving out games they hosted
+// and, with publicOnly, private games.
+func (r Repository) GetFavouriteTopics(userID uint, limit int, publicOnly bool) []entity.TopicStats {
+	var topics []entity.TopicStats
+
+	query := r.DB.Model(&entity.GameSession{}).
+		Select("games.topic, count(*) as games").
+		Joins("INNER JOIN games ON games.id = game_sessions.game_id").
+		Joins("LEFT JOIN game_instances ON game_instances.id = game_sessions.instance_id").
+		Where("game_sessions.user_id = ? and game_sessions.user_id <> coalesce(game_instances.host_id, games.owner)", userID)
+	if publicOnly {
+		query = query.Where("games.public = ?", true)
+	}
+
+	query.Group("games.topic").
+		Order("games DESC, games.topic").
+		Limit(limit).
+		Scan(&topics)
+
+	return topics
+}
@@ -57,10 +57,79 @@ func (r Repository) CreateUser(e *entity.User) *entity.User {
 }
 
 func (r Repository) UpdateUser(e *entity.User) *entity.User {
-	r.DB.Where("id = ?", e.ID).Updates(&e)
+	// private_stats is selected so that turning it off is saved as well.
+	r.DB.Where("id = ?", e.ID).Select("name", "picture", "private_stats").Updates(&e)
 	return e
 }
 
 func (r Repository) DeleteUser(e *entity.User) {
 	r.DB.Unscoped().Delete(&e)
 }
+
+func (r Repository) GetRatings(IDs []uint) map[uint]float64 {
+	var users []entity.User
+	r.DB.Select("id, rating").Where("id in ?", IDs).Find(&users)
+
+	ratings := make(map[uint]float64, len(users))
+	for _, user := range users {
+		ratings[user.ID] = user.Rating
+	}
+	return ratings
+}
+
+// SaveRatings records changes in the rating history and moves each user to their new rating.
+func (r Repository) SaveRatings(changes []*entity.RatingChange) {
+	r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(changes).Error; err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			err := tx.Model(&entity.User{}).Where("id = ?", change.UserID).Updates(map[string]any{
+				"rating":      change.Rating,
+				"rated_games": gorm.Expr("rated_games + 1"),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r Repository) GetRatingHistory(userID uint, limit int) []entity.RatingChange {
+	var history []entity.RatingChange
+	r.DB.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&history)
+	return history
+}
+
+func (r Repository) GetAchievements(userID uint) []entity.Achievement {
+	var achievements []entity.Achievement
+	r.DB.Where("user_id = ?", userID).Order("unlocked_at").Find(&achievements)
+	return achievements
+}
+
+func (r Repository) GetXP(IDs []uint) map[uint]int64 {
+	var users []entity.User
+	r.DB.Select("id, xp").Where("id in ?", IDs).Find(&users)
+
+	xp := make(map[uint]int64, len(users))
+	for _, user := range users {
+		xp[user.ID] = user.XP
+	}
+	return xp
+}
+
+func (r Repository) AddXP(gains map[uint]int64) {
+	r.DB.Transaction(func(tx *gorm.DB) error {
+		for userID, xp := range gains {
+			err := tx.Model(&entity.User{}).Where("id = ?", userID).Update("xp", gorm.Expr("xp + ?", xp)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
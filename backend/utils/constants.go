@@ -13,7 +13,8 @@ const (
 	ReceiveChat    = "RECEIVE_CHAT"
 	Ping           = "PING"
 	Pong           = "PONG"
-	MessageError   = "MESSAGE_ERROR"
+	Welcome        = "WELCOME"
+	Resync         = "RESYNC"
 
 	Standby    = "GAME_STANDBY"
 	Starting   = "GAME_STARTING"
@@ -25,15 +26,51 @@ const (
 	RoundFinished   = "ROUND_FINISHED"
 	AnswerAccepted  = "ANSWER_ACCEPTED"
 
-	GameNotFound  = "GAME_NOT_FOUND"
-	AlreadyInGame = "ALREADY_IN_GAME"
-	NotInGame     = "NOT_IN_GAME"
-	NotOwner      = "NOT_OWNER"
-
 	JoinedGame   = "JOINED_GAME"
 	LeftGame     = "LEFT_GAME"
 	GameDeleted  = "GAME_DELETED"
 	UserLeft     = "USER_LEFT"
 	UserJoined   = "USER_JOINED"
 	UserAnswered = "USER_ANSWERED"
+
+	AchievementUnlocked = "ACHIEVEMENT_UNLOCKED"
+
+	LobbyExpired = "LOBBY_EXPIRED"
+)
+
+// ErrorCode is the machine-readable reason sent in the `code` field of socket error replies.
+type ErrorCode string
+
+const (
+	InvalidMessage   ErrorCode = "INVALID_MESSAGE"
+	UnknownMessage   ErrorCode = "UNKNOWN_MESSAGE"
+	InvalidData      ErrorCode = "INVALID_DATA"
+	InitFailed       ErrorCode = "INIT_FAILED"
+	ConnectionError  ErrorCode = "CONNECTION_ERROR"
+	GameNotFound     ErrorCode = "GAME_NOT_FOUND"
+	GameNotPublished ErrorCode = "GAME_NOT_PUBLISHED"
+	AlreadyInGame    ErrorCode = "ALREADY_IN_GAME"
+	NotInGame        ErrorCode = "NOT_IN_GAME"
+	NotOwner         ErrorCode = "NOT_OWNER"
+	InvalidGameState ErrorCode = "INVALID_GAME_STATE"
+	RoundNotStarted  ErrorCode = "ROUND_NOT_STARTED"
+	RoundNotFinished ErrorCode = "ROUND_NOT_FINISHED"
+	DeadlinePassed   ErrorCode = "DEADLINE_PASSED"
 )
+
+var ErrorCodes = []ErrorCode{
+	InvalidMessage,
+	UnknownMessage,
+	InvalidData,
+	InitFailed,
+	ConnectionError,
+	GameNotFound,
+	GameNotPublished,
+	AlreadyInGame,
+	NotInGame,
+	NotOwner,
+	InvalidGameState,
+	RoundNotStarted,
+	RoundNotFinished,
+	DeadlinePassed,
+}
@@ -33,6 +33,7 @@ export const useGameStore = defineStore('GameStore', () => {
   const gameId = ref(0);
   const inviteCode = ref('');
   const owner = ref(null);
+  const revision = ref(0); // revision the quiz was loaded at, so stale edits are refused
   const isOwner = computed(() => authStore.user.id === owner.value);
 
   const topic = ref('' || 'Game topic');
@@ -167,6 +168,7 @@ export const useGameStore = defineStore('GameStore', () => {
           roundTime: parseInt(roundTime.value),
           points: parseInt(points.value),
           questions: questions.value,
+          revision: revision.value,
         },
         {
           baseURL: config.public.apiUrl,
@@ -180,6 +182,8 @@ export const useGameStore = defineStore('GameStore', () => {
     } catch (error) {
       const message = error.response.data.error;
       errorStore.message = message;
+      // Someone else saved the quiz first, show it as it is now
+      if (error.response.status === 409) fillGameFields(error.response.data.game);
     }
   }
 
@@ -191,6 +195,7 @@ export const useGameStore = defineStore('GameStore', () => {
     roundTime.value = data.roundTime;
     questions.value = data.questions;
     owner.value = data.ownerId;
+    revision.value = data.revision;
     console.log(questions.value);
   }
 